@@ -0,0 +1,157 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// IncMajor returns the next major version. Lower components are reset and
+// build metadata dropped; a prerelease of a major release (2.0.0-rc.1) is
+// promoted to that release (2.0.0).
+func (ver Version) IncMajor() Version {
+	next := ver.withPrerelease(nil)
+	if !(ver.IsPrerelease() && ver.Minor == 0 && ver.Patch == 0) {
+		next.Major++
+		next.Minor = 0
+		next.Patch = 0
+	}
+	return next.withRaw(ver)
+}
+
+// IncMinor returns the next minor version. The patch is reset and build
+// metadata dropped; a prerelease of a minor release (1.3.0-rc.2) is promoted
+// to that release (1.3.0).
+func (ver Version) IncMinor() Version {
+	next := ver.withPrerelease(nil)
+	if !(ver.IsPrerelease() && ver.Patch == 0) {
+		next.Minor++
+		next.Patch = 0
+	}
+	return next.withRaw(ver)
+}
+
+// IncPatch returns the next patch version with build metadata dropped. A
+// prerelease (1.2.4-rc.1) is promoted to its release (1.2.4).
+func (ver Version) IncPatch() Version {
+	next := ver.withPrerelease(nil)
+	if !ver.IsPrerelease() {
+		next.Patch++
+	}
+	return next.withRaw(ver)
+}
+
+// IncPrerelease returns the next prerelease version for the given identifier
+// (for example "rc"):
+//
+//	1.2.3        -> 1.2.4-rc.1
+//	1.3.0-rc.1   -> 1.3.0-rc.2
+//	1.3.0-rc     -> 1.3.0-rc.1
+//	1.3.0-beta.2 -> 1.3.0-rc.1
+//
+// An empty id increments the existing prerelease counter. It is an error if
+// id is not a valid identifier or the result would not have higher
+// precedence than ver (e.g. rc -> beta).
+func (ver Version) IncPrerelease(id string) (Version, error) {
+	if id != "" {
+		if _, err := parsePrerelease(id, id, Strict); err != nil {
+			return Version{}, err
+		}
+	}
+
+	pre := ver.prerelease()
+	var next Version
+	switch {
+	case len(pre) == 0:
+		if id == "" {
+			return Version{}, fmt.Errorf("%w: %s is not a prerelease, an identifier is required", ErrInvalidVersion, ver)
+		}
+		next = ver.IncPatch().withPrerelease(append(splitIdentifiers(id), "1"))
+	case id == "" || strings.HasPrefix(ver.PrereleaseString()+".", id+"."):
+		next = ver.withPrerelease(incrementCounter(pre))
+	default:
+		next = ver.withPrerelease(append(splitIdentifiers(id), "1"))
+	}
+	next.Build = nil
+
+	if !next.GreaterThan(ver) {
+		return Version{}, fmt.Errorf("%w: %s does not follow %s", ErrInvalidVersion, next, ver)
+	}
+	return next.withRaw(ver), nil
+}
+
+// SetPrerelease returns a copy of ver with the given dot-separated prerelease
+// (e.g. "rc.1"). An empty string clears the prerelease.
+func (ver Version) SetPrerelease(pre string) (Version, error) {
+	if pre == "" {
+		return ver.withPrerelease(nil).withRaw(ver), nil
+	}
+	ids, err := parsePrerelease(pre, pre, Strict)
+	if err != nil {
+		return Version{}, err
+	}
+	return ver.withPrerelease(ids).withRaw(ver), nil
+}
+
+// SetMetadata returns a copy of ver with the given dot-separated build
+// metadata (e.g. "build.42"). An empty string clears the metadata.
+func (ver Version) SetMetadata(meta string) (Version, error) {
+	next := ver
+	next.Build = nil
+	if meta != "" {
+		build, err := parseBuild(meta, meta)
+		if err != nil {
+			return Version{}, err
+		}
+		next.Build = build
+	}
+	return next.withRaw(ver), nil
+}
+
+// withPrerelease returns a copy of ver with the prerelease replaced and the
+// build metadata dropped, keeping the deprecated Prefix field in sync.
+func (ver Version) withPrerelease(ids []Identifier) Version {
+	next := ver
+	next.Prerelease = ids
+	next.Build = nil
+	// clear Prefix first, PrereleaseString falls back to it
+	next.Prefix = ""
+	next.Prefix = next.PrereleaseString()
+	return next
+}
+
+// withRaw sets Raw to the canonical string, keeping the "v" prefix of the
+// version it was derived from.
+func (ver Version) withRaw(from Version) Version {
+	ver.Raw = ver.String()
+	if strings.HasPrefix(from.Raw, "v") {
+		ver.Raw = "v" + ver.Raw
+	}
+	return ver
+}
+
+// incrementCounter increments the last numeric identifier, or appends a
+// counter of 1 if there is none.
+func incrementCounter(pre []Identifier) []Identifier {
+	next := make([]Identifier, len(pre))
+	copy(next, pre)
+	for i := len(next) - 1; i >= 0; i-- {
+		if next[i].IsNumeric() {
+			n, err := strconv.ParseUint(string(next[i]), 10, 64)
+			if err == nil {
+				next[i] = Identifier(strconv.FormatUint(n+1, 10))
+				return next
+			}
+		}
+	}
+	return append(next, "1")
+}
+
+func splitIdentifiers(s string) []Identifier {
+	parts := strings.Split(s, ".")
+	ids := make([]Identifier, len(parts))
+	for i, p := range parts {
+		ids[i] = Identifier(p)
+	}
+	return ids
+}
@@ -0,0 +1,149 @@
+package version
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInc(t *testing.T) {
+	tests := []struct {
+		input string
+		major string
+		minor string
+		patch string
+	}{
+		{"1.2.3", "2.0.0", "1.3.0", "1.2.4"},
+		{"v1.2.3", "v2.0.0", "v1.3.0", "v1.2.4"},
+		{"0.0.0", "1.0.0", "0.1.0", "0.0.1"},
+		{"1.2.3+build.5", "2.0.0", "1.3.0", "1.2.4"},
+		{"1.3.0-rc.2", "2.0.0", "1.3.0", "1.3.0"},
+		{"2.0.0-rc.1", "2.0.0", "2.0.0", "2.0.0"},
+		{"1.2.4-beta", "2.0.0", "1.3.0", "1.2.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v := MustParse(tt.input)
+			if got := v.IncMajor().Raw; got != tt.major {
+				t.Errorf("IncMajor() = %s, want %s", got, tt.major)
+			}
+			if got := v.IncMinor().Raw; got != tt.minor {
+				t.Errorf("IncMinor() = %s, want %s", got, tt.minor)
+			}
+			if got := v.IncPatch().Raw; got != tt.patch {
+				t.Errorf("IncPatch() = %s, want %s", got, tt.patch)
+			}
+			if v.String() != MustParse(tt.input).String() {
+				t.Errorf("Inc* must not modify the receiver, got %s", v)
+			}
+		})
+	}
+}
+
+func TestInc_ParsedResult(t *testing.T) {
+	next := MustParse("1.3.0-rc.2+build").IncPatch()
+	if next.IsPrerelease() || next.Prefix != "" || len(next.Build) != 0 {
+		t.Errorf("IncPatch() = %+v, want prerelease and build cleared", next)
+	}
+	if !next.Equal(MustParse("1.3.0")) {
+		t.Errorf("IncPatch() = %s, want 1.3.0", next)
+	}
+}
+
+func TestIncPrerelease(t *testing.T) {
+	tests := []struct {
+		input string
+		id    string
+		want  string
+	}{
+		{"1.2.3", "rc", "1.2.4-rc.1"},
+		{"v1.2.3", "rc", "v1.2.4-rc.1"},
+		{"1.3.0-rc.1", "rc", "1.3.0-rc.2"},
+		{"1.3.0-rc.1", "", "1.3.0-rc.2"},
+		{"1.3.0-rc.9", "", "1.3.0-rc.10"},
+		{"1.3.0-rc", "rc", "1.3.0-rc.1"},
+		{"1.3.0-beta.2", "rc", "1.3.0-rc.1"},
+		{"1.3.0-alpha.1+meta", "alpha", "1.3.0-alpha.2"},
+		{"1.3.0-1", "", "1.3.0-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+"/"+tt.id, func(t *testing.T) {
+			got, err := MustParse(tt.input).IncPrerelease(tt.id)
+			if err != nil {
+				t.Fatalf("IncPrerelease(%q) error = %v", tt.id, err)
+			}
+			if got.Raw != tt.want {
+				t.Errorf("IncPrerelease(%q) = %s, want %s", tt.id, got.Raw, tt.want)
+			}
+			if got.Prefix != got.PrereleaseString() {
+				t.Errorf("Prefix = %q, should mirror Prerelease %q", got.Prefix, got.PrereleaseString())
+			}
+		})
+	}
+}
+
+func TestIncPrerelease_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		id    string
+	}{
+		{"1.3.0-rc.1", "beta"},
+		{"1.2.3", ""},
+		{"1.2.3", "rc_1"},
+		{"1.2.3", "01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input+"/"+tt.id, func(t *testing.T) {
+			_, err := MustParse(tt.input).IncPrerelease(tt.id)
+			if !errors.Is(err, ErrInvalidVersion) {
+				t.Errorf("IncPrerelease(%q) error = %v, want ErrInvalidVersion", tt.id, err)
+			}
+		})
+	}
+}
+
+func TestSetPrerelease(t *testing.T) {
+	v, err := MustParse("v1.2.3+build").SetPrerelease("rc.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Raw != "v1.2.3-rc.1" {
+		t.Errorf("SetPrerelease() = %s, want v1.2.3-rc.1", v.Raw)
+	}
+
+	v, err = v.SetPrerelease("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.IsPrerelease() || v.Raw != "v1.2.3" {
+		t.Errorf("SetPrerelease(\"\") = %s, want v1.2.3", v.Raw)
+	}
+
+	if _, err := v.SetPrerelease("rc..1"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("SetPrerelease(invalid) error = %v, want ErrInvalidVersion", err)
+	}
+}
+
+func TestSetMetadata(t *testing.T) {
+	v, err := MustParse("1.2.3-rc.1").SetMetadata("build.42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Raw != "1.2.3-rc.1+build.42" {
+		t.Errorf("SetMetadata() = %s, want 1.2.3-rc.1+build.42", v.Raw)
+	}
+
+	v, err = v.SetMetadata("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Raw != "1.2.3-rc.1" {
+		t.Errorf("SetMetadata(\"\") = %s, want 1.2.3-rc.1", v.Raw)
+	}
+
+	if _, err := v.SetMetadata("a+b"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("SetMetadata(invalid) error = %v, want ErrInvalidVersion", err)
+	}
+}
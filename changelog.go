@@ -0,0 +1,171 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidChangelog is returned (wrapped in a *ChangelogError) when
+// changelog text cannot be parsed.
+var ErrInvalidChangelog = errors.New("invalid changelog")
+
+// ChangelogError reports the line of a changelog that could not be parsed.
+type ChangelogError struct {
+	// 1-based line number
+	Line int
+	// underlying error, e.g. a *ParseError for an invalid version heading
+	Err error
+}
+
+func (e *ChangelogError) Error() string {
+	return fmt.Sprintf("invalid changelog: line %d: %v", e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ChangelogError) Unwrap() error {
+	return e.Err
+}
+
+// Is allows errors.Is(err, ErrInvalidChangelog).
+func (e *ChangelogError) Is(target error) bool {
+	return target == ErrInvalidChangelog
+}
+
+// defaultSection collects entries that appear before any "###" heading.
+const defaultSection = "Changes"
+
+// Changelog is a parsed Keep a Changelog document, see https://keepachangelog.com.
+type Changelog struct {
+	// releases in document order, usually newest first
+	Releases []Release
+}
+
+// Release is one "## [version] - date" section of a changelog.
+type Release struct {
+	// release version, zero for the Unreleased section
+	Version Version
+	// release date, zero if the heading has none
+	Date time.Time
+	// entries by section heading ("Added", "Fixed", ...). Entries that appear
+	// before any section heading are collected under "Changes".
+	Sections map[string][]string
+	// whether this is the "Unreleased" section
+	Unreleased bool
+}
+
+var (
+	releaseHeading = regexp.MustCompile(`^##\s+\[?([^\]\s(]+)\]?(?:\([^)]*\))?(?:\s+-\s+(\d{4}-\d{2}-\d{2}))?(?:\s.*)?$`)
+	linkReference  = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+	// bare heading text that names a version rather than e.g. "Notes"
+	versionLike = regexp.MustCompile(`^[vV]?\d`)
+)
+
+// ParseChangelog parses Keep a Changelog Markdown. Text before the first
+// release heading, level 2 sections that are not releases, such as
+// "## Notes", and link reference definitions are ignored. A release heading
+// is "Unreleased", a version, or anything in brackets; one with an invalid
+// version or date returns a *ChangelogError.
+func ParseChangelog(text string) (Changelog, error) {
+	var log Changelog
+	var cur *Release
+	section := defaultSection
+	var entry *string
+
+	for i, raw := range strings.Split(text, "\n") {
+		lineNo := i + 1
+		line := strings.TrimRight(raw, " \t\r")
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(line, "## "):
+			rel, ok, err := parseReleaseHeading(line)
+			if err != nil {
+				return Changelog{}, &ChangelogError{Line: lineNo, Err: err}
+			}
+			cur, section, entry = nil, defaultSection, nil
+			if ok {
+				log.Releases = append(log.Releases, rel)
+				cur = &log.Releases[len(log.Releases)-1]
+			}
+
+		case cur == nil:
+			// title and introduction
+
+		case strings.HasPrefix(line, "### "):
+			section = strings.TrimSpace(strings.TrimPrefix(line, "### "))
+			entry = nil
+
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			cur.Sections[section] = append(cur.Sections[section], strings.TrimSpace(line[2:]))
+			entries := cur.Sections[section]
+			entry = &entries[len(entries)-1]
+
+		case trimmed == "" || linkReference.MatchString(trimmed):
+			entry = nil
+
+		case entry != nil && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")):
+			// continuation of a wrapped entry
+			*entry += " " + trimmed
+		}
+	}
+	return log, nil
+}
+
+// parseReleaseHeading parses a "## " heading; ok is false if it does not
+// head a release.
+func parseReleaseHeading(line string) (rel Release, ok bool, err error) {
+	bracketed := strings.HasPrefix(strings.TrimSpace(line[2:]), "[")
+	m := releaseHeading.FindStringSubmatch(line)
+	if m == nil {
+		if bracketed {
+			return Release{}, false, fmt.Errorf("malformed release heading %q", line)
+		}
+		return Release{}, false, nil
+	}
+	rel = Release{Sections: map[string][]string{}}
+	if strings.EqualFold(m[1], "unreleased") {
+		rel.Unreleased = true
+		return rel, true, nil
+	}
+	if !bracketed && !versionLike.MatchString(m[1]) {
+		return Release{}, false, nil
+	}
+	v, err := Parse(m[1])
+	if err != nil {
+		return Release{}, false, err
+	}
+	rel.Version = v
+	if m[2] != "" {
+		d, err := time.Parse("2006-01-02", m[2])
+		if err != nil {
+			return Release{}, false, fmt.Errorf("invalid release date %q", m[2])
+		}
+		rel.Date = d
+	}
+	return rel, true, nil
+}
+
+// Release returns the release with the same precedence as v.
+func (c Changelog) Release(v Version) (Release, bool) {
+	for _, r := range c.Releases {
+		if !r.Unreleased && r.Version.Equal(v) {
+			return r, true
+		}
+	}
+	return Release{}, false
+}
+
+// ChangesSince returns the released versions newer than v, in document order.
+// The Unreleased section is never included.
+func (c Changelog) ChangesSince(v Version) []Release {
+	var out []Release
+	for _, r := range c.Releases {
+		if !r.Unreleased && r.Version.GreaterThan(v) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+package version
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const sampleChangelog = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+- this list is part of the introduction
+
+## [Unreleased]
+
+### Added
+
+- Work in progress
+
+## [1.2.0](https://github.com/org/app/compare/v1.1.0...v1.2.0) - 2024-03-01
+
+### Added
+
+- New bump command
+- Changelog parsing that spans
+  two lines
+
+### Fixed
+
+* Crash on empty tags
+
+## [1.1.0] - 2024-02-01 [YANKED]
+
+- Entry without a section
+
+## v1.0.0
+
+### Added
+
+- Initial release
+
+[1.2.0]: https://github.com/org/app/compare/v1.1.0...v1.2.0
+[1.1.0]: https://github.com/org/app/compare/v1.0.0...v1.1.0
+`
+
+func TestParseChangelog(t *testing.T) {
+	log, err := ParseChangelog(sampleChangelog)
+	if err != nil {
+		t.Fatalf("ParseChangelog() error = %v", err)
+	}
+	if len(log.Releases) != 4 {
+		t.Fatalf("len(Releases) = %d, want 4", len(log.Releases))
+	}
+
+	unreleased := log.Releases[0]
+	if !unreleased.Unreleased {
+		t.Error("Releases[0] should be Unreleased")
+	}
+	if !reflect.DeepEqual(unreleased.Sections["Added"], []string{"Work in progress"}) {
+		t.Errorf("Unreleased Added = %v", unreleased.Sections["Added"])
+	}
+
+	r := log.Releases[1]
+	if r.Version.String() != "1.2.0" {
+		t.Errorf("Version = %s, want 1.2.0", r.Version)
+	}
+	if !r.Date.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v, want 2024-03-01", r.Date)
+	}
+	wantAdded := []string{"New bump command", "Changelog parsing that spans two lines"}
+	if !reflect.DeepEqual(r.Sections["Added"], wantAdded) {
+		t.Errorf("Added = %q, want %q", r.Sections["Added"], wantAdded)
+	}
+	if !reflect.DeepEqual(r.Sections["Fixed"], []string{"Crash on empty tags"}) {
+		t.Errorf("Fixed = %q", r.Sections["Fixed"])
+	}
+
+	r = log.Releases[2]
+	if r.Version.String() != "1.1.0" || r.Date.IsZero() {
+		t.Errorf("Releases[2] = %s %v, want 1.1.0 with date", r.Version, r.Date)
+	}
+	if !reflect.DeepEqual(r.Sections["Changes"], []string{"Entry without a section"}) {
+		t.Errorf("Changes = %q", r.Sections["Changes"])
+	}
+
+	r = log.Releases[3]
+	if r.Version.Raw != "v1.0.0" || !r.Date.IsZero() {
+		t.Errorf("Releases[3] = %q %v, want v1.0.0 without date", r.Version.Raw, r.Date)
+	}
+	if len(r.Sections["Added"]) != 1 {
+		t.Errorf("link references should not be entries, got %q", r.Sections)
+	}
+}
+
+func TestParseChangelog_OtherSections(t *testing.T) {
+	text := "# Changelog\n\n## Notes\n\n- not a release\n\n## [1.1.0]\n\n- fix\n\n" +
+		"## Migration guide\n\n### Added\n\n- not part of 1.1.0\n\n## 1.0.0\n\n- first\n"
+	log, err := ParseChangelog(text)
+	if err != nil {
+		t.Fatalf("ParseChangelog() error = %v", err)
+	}
+	if len(log.Releases) != 2 || log.Releases[0].Version.String() != "1.1.0" || log.Releases[1].Version.String() != "1.0.0" {
+		t.Fatalf("Releases = %+v, want 1.1.0 and 1.0.0", log.Releases)
+	}
+	want := map[string][]string{"Changes": {"fix"}}
+	if !reflect.DeepEqual(log.Releases[0].Sections, want) {
+		t.Errorf("1.1.0 Sections = %q, want %q", log.Releases[0].Sections, want)
+	}
+}
+
+func TestParseChangelog_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		line    int
+		version bool
+	}{
+		{"invalid version", "# Changelog\n\n## [1.2] - 2024-01-01\n", 3, true},
+		{"invalid date", "## [1.2.0] - 2024-13-45\n", 1, false},
+		{"malformed heading", "## [(link)]\n", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseChangelog(tt.text)
+			if !errors.Is(err, ErrInvalidChangelog) {
+				t.Fatalf("error = %v, want ErrInvalidChangelog", err)
+			}
+			var cerr *ChangelogError
+			if !errors.As(err, &cerr) || cerr.Line != tt.line {
+				t.Errorf("error = %#v, want line %d", err, tt.line)
+			}
+			if got := errors.Is(err, ErrInvalidVersion); got != tt.version {
+				t.Errorf("errors.Is(err, ErrInvalidVersion) = %v, want %v", got, tt.version)
+			}
+		})
+	}
+}
+
+func TestChangelog_ChangesSince(t *testing.T) {
+	log, err := ParseChangelog(sampleChangelog)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	since := log.ChangesSince(MustParse("1.0.0"))
+	if len(since) != 2 || since[0].Version.String() != "1.2.0" || since[1].Version.String() != "1.1.0" {
+		t.Errorf("ChangesSince(1.0.0) = %+v, want 1.2.0 and 1.1.0", since)
+	}
+	if got := log.ChangesSince(MustParse("1.2.0")); len(got) != 0 {
+		t.Errorf("ChangesSince(latest) = %+v, want none", got)
+	}
+	if got := log.ChangesSince(MustParse("1.2.0-rc.1")); len(got) != 1 {
+		t.Errorf("ChangesSince(1.2.0-rc.1) should include 1.2.0, got %+v", got)
+	}
+}
+
+func TestChangelog_Release(t *testing.T) {
+	log, err := ParseChangelog(sampleChangelog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, ok := log.Release(MustParse("v1.1.0"))
+	if !ok || r.Version.String() != "1.1.0" {
+		t.Errorf("Release(1.1.0) = %+v, %v", r, ok)
+	}
+	if _, ok := log.Release(MustParse("9.9.9")); ok {
+		t.Error("Release(9.9.9) should not be found")
+	}
+}
+
+func TestSetChangelog_Parses(t *testing.T) {
+	resetState()
+	if err := SetChangelog(sampleChangelog); err != nil {
+		t.Fatalf("SetChangelog() error = %v", err)
+	}
+
+	a := App()
+	if a.Changelog != sampleChangelog {
+		t.Error("raw changelog should be kept")
+	}
+	if len(a.Changes.Releases) != 4 {
+		t.Errorf("len(Changes.Releases) = %d, want 4", len(a.Changes.Releases))
+	}
+	if got := ChangesSince(MustParse("1.1.0")); len(got) != 1 {
+		t.Errorf("ChangesSince(1.1.0) = %+v, want 1 release", got)
+	}
+}
+
+func TestSetChangelog_ParseError(t *testing.T) {
+	resetState()
+	err := SetChangelog("## [not-a-version]\n- x\n")
+	if !errors.Is(err, ErrInvalidChangelog) {
+		t.Fatalf("SetChangelog() error = %v, want ErrInvalidChangelog", err)
+	}
+	if App().Changelog == "" {
+		t.Error("raw changelog should be kept on parse error")
+	}
+	if len(App().Changes.Releases) != 0 {
+		t.Error("parsed model should stay empty on parse error")
+	}
+}
+
+func TestSetChangelogFromFile_ParseError(t *testing.T) {
+	resetState()
+	path := filepath.Join(t.TempDir(), "CHANGELOG.md")
+	if err := os.WriteFile(path, []byte("## [1.0] - 2024-01-01\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetChangelogFromFile(path); !errors.Is(err, ErrInvalidChangelog) {
+		t.Errorf("SetChangelogFromFile() error = %v, want ErrInvalidChangelog", err)
+	}
+}
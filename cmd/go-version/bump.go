@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+const bumpUsage = `Compute the next version from the latest SemVer tag and optionally tag it
+
+Usage:
+  go-version bump [major|minor|patch|prerelease] [options]
+
+The latest tag reachable from HEAD that is a valid SemVer version (with an
+optional "v" prefix) is used as the current version; other tags are ignored.
+Without tags the current version is v0.0.0. The command refuses to run on a
+dirty working tree or when HEAD already has a SemVer tag.
+
+Options:
+      --pre          Prerelease identifier for 'prerelease' (e.g. rc, beta)
+      --tag          Create an annotated tag for the new version
+  -m, --message      Tag message (default: "Release <version>")
+      --push         Push the new tag to the remote (implies --tag)
+      --remote       Remote to push to (default: origin)
+      --dry-run      Print what would be done without creating or pushing tags
+
+Examples:
+  go-version bump                          # Print the next patch version
+  go-version bump minor --tag              # Tag the next minor version
+  go-version bump prerelease --pre rc      # v1.2.3 -> v1.2.4-rc.1, v1.3.0-rc.1 -> v1.3.0-rc.2
+  go-version bump patch --push             # Tag and push, e.g. in a release job
+  go-version bump major --push --dry-run   # Show what a major release would do
+`
+
+func cmdBump(args []string) {
+	fs := flag.NewFlagSet("bump", flag.ExitOnError)
+	fs.Usage = func() { _, _ = os.Stdout.WriteString(bumpUsage) }
+
+	var pre, message, remote string
+	var tag, push, dryRun bool
+	fs.StringVar(&pre, "pre", "", "Prerelease identifier")
+	fs.BoolVar(&tag, "tag", false, "Create an annotated tag")
+	fs.StringVar(&message, "m", "", "Tag message")
+	fs.StringVar(&message, "message", "", "Tag message")
+	fs.BoolVar(&push, "push", false, "Push the new tag")
+	fs.StringVar(&remote, "remote", "origin", "Remote to push to")
+	fs.BoolVar(&dryRun, "dry-run", false, "Do not create or push tags")
+
+	// Allow the part before or after the options. Flag parsing stops at the
+	// first argument, so nothing may follow a trailing part.
+	part := "patch"
+	leading := len(args) > 0 && !strings.HasPrefix(args[0], "-")
+	if leading {
+		part, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+	rest := fs.Args()
+	if !leading && len(rest) > 0 {
+		part, rest = rest[0], rest[1:]
+	}
+	if len(rest) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected argument %q, give the part before or after all options\n", rest[0])
+		os.Exit(1)
+	}
+
+	if gitCommand("rev-parse", "--git-dir") == "" {
+		fmt.Fprintln(os.Stderr, "Error: not a git repository")
+		os.Exit(1)
+	}
+	if dirty := gitCommand(dirtyStatusArgs...); dirty != "" {
+		fmt.Fprintf(os.Stderr, "Error: working tree has uncommitted changes:\n%s\n", dirty)
+		os.Exit(1)
+	}
+	if tags := semverTags(gitLines("tag", "--points-at", "HEAD")); len(tags) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: HEAD is already tagged as %s\n", tags[len(tags)-1].Raw)
+		os.Exit(1)
+	}
+
+	current := latestTag(gitLines("tag", "--merged", "HEAD"))
+	next, err := nextVersion(current, part, pre)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Current version: %s\n", current.Raw)
+	fmt.Printf("Next version:    %s\n", next.Raw)
+
+	if !tag && !push {
+		return
+	}
+	if message == "" {
+		message = "Release " + next.Raw
+	}
+
+	if dryRun {
+		fmt.Printf("Would create tag %s (%q)\n", next.Raw, message)
+		if push {
+			fmt.Printf("Would push tag %s to %s\n", next.Raw, remote)
+		}
+		return
+	}
+
+	if err := runGit("tag", "-a", next.Raw, "-m", message); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating tag: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created tag %s\n", next.Raw)
+
+	if push {
+		if err := runGit("push", remote, next.Raw); err != nil {
+			fmt.Fprintf(os.Stderr, "Error pushing tag: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Pushed tag %s to %s\n", next.Raw, remote)
+	}
+}
+
+// nextVersion bumps current by part: major, minor, patch or prerelease.
+// The prerelease identifier pre is only accepted for prerelease.
+func nextVersion(current version.Version, part, pre string) (version.Version, error) {
+	switch part {
+	case "major", "minor", "patch":
+		if pre != "" {
+			return version.Version{}, fmt.Errorf("--pre %s only applies to prerelease, not %s", pre, part)
+		}
+	}
+	switch part {
+	case "major":
+		return current.IncMajor(), nil
+	case "minor":
+		return current.IncMinor(), nil
+	case "patch":
+		return current.IncPatch(), nil
+	case "prerelease", "pre":
+		return current.IncPrerelease(pre)
+	}
+	return version.Version{}, fmt.Errorf("unknown version part %q (want major, minor, patch or prerelease)", part)
+}
+
+// latestTag returns the highest SemVer tag, or v0.0.0 if there is none.
+func latestTag(tags []string) version.Version {
+	versions := semverTags(tags)
+	if len(versions) == 0 {
+		return version.MustParse("v0.0.0")
+	}
+	return versions[len(versions)-1]
+}
+
+// semverTags parses tags as versions, dropping non-SemVer tags, and returns
+// them in ascending order.
+func semverTags(tags []string) version.Collection {
+	var versions version.Collection
+	for _, t := range tags {
+		if v, err := version.Parse(t); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	sort.Stable(versions)
+	return versions
+}
+
+// gitLines runs a git command and returns its non-empty output lines.
+func gitLines(args ...string) []string {
+	out := gitCommand(args...)
+	if out == "" {
+		return nil
+	}
+	return strings.Split(out, "\n")
+}
+
+// runGit runs a git command that modifies the repository, passing its output
+// through so failures are visible.
+func runGit(args ...string) error {
+	cmd := exec.Command("git", args...) // #nosec G204 -- arguments are git subcommands and validated versions
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a git repository with a single commit in a temp dir.
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("skipping: git not installed")
+	}
+	dir := t.TempDir()
+	runIn(t, dir, "git", "init", "-q")
+	runIn(t, dir, "git", "config", "user.name", "Test")
+	runIn(t, dir, "git", "config", "user.email", "test@example.com")
+	runIn(t, dir, "git", "config", "commit.gpgsign", "false")
+	runIn(t, dir, "git", "config", "tag.gpgsign", "false")
+	commitFile(t, dir, "README", "initial", "chore: initial commit")
+	return dir
+}
+
+// commitFile writes content to name and commits it with the given message.
+func commitFile(t *testing.T, dir, name, content, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runIn(t, dir, "git", "add", name)
+	runIn(t, dir, "git", "commit", "-q", "-m", message)
+}
+
+// runIn runs a command in dir and fails the test on error.
+func runIn(t *testing.T, dir string, name string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s %s failed: %v\n%s", name, strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// runBinary runs the test binary in dir and returns combined output and error.
+func runBinary(t *testing.T, dir string, args ...string) (string, error) {
+	t.Helper()
+	cmd := exec.Command(buildTestBinary(t), args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// --- nextVersion / latestTag tests ---
+
+func TestLatestTag(t *testing.T) {
+	tags := []string{"v1.2.3", "release-2024", "v1.10.0", "v1.10.0-rc.1", "latest", "v1.9.9", "2.0"}
+	if got := latestTag(tags).Raw; got != "v1.10.0" {
+		t.Errorf("latestTag() = %s, want v1.10.0", got)
+	}
+	if got := latestTag(nil).Raw; got != "v0.0.0" {
+		t.Errorf("latestTag(nil) = %s, want v0.0.0", got)
+	}
+	if got := latestTag([]string{"nightly"}).Raw; got != "v0.0.0" {
+		t.Errorf("latestTag(non-semver) = %s, want v0.0.0", got)
+	}
+}
+
+func TestNextVersion(t *testing.T) {
+	tests := []struct {
+		current string
+		part    string
+		pre     string
+		want    string
+	}{
+		{"v1.2.3", "patch", "", "v1.2.4"},
+		{"v1.2.3", "minor", "", "v1.3.0"},
+		{"v1.2.3", "major", "", "v2.0.0"},
+		{"v1.2.3", "prerelease", "rc", "v1.2.4-rc.1"},
+		{"v1.3.0-rc.1", "prerelease", "", "v1.3.0-rc.2"},
+		{"v1.3.0-rc.2", "patch", "", "v1.3.0"},
+		{"1.0.0", "patch", "", "1.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.current+"/"+tt.part, func(t *testing.T) {
+			got, err := nextVersion(latestTag([]string{tt.current}), tt.part, tt.pre)
+			if err != nil {
+				t.Fatalf("nextVersion() error = %v", err)
+			}
+			if got.Raw != tt.want {
+				t.Errorf("nextVersion() = %s, want %s", got.Raw, tt.want)
+			}
+		})
+	}
+
+	if _, err := nextVersion(latestTag(nil), "huge", ""); err == nil {
+		t.Error("expected error for unknown part")
+	}
+	if _, err := nextVersion(latestTag([]string{"v1.0.0"}), "prerelease", ""); err == nil {
+		t.Error("expected error for prerelease without identifier on a release")
+	}
+	if _, err := nextVersion(latestTag([]string{"v1.0.0"}), "minor", "rc"); err == nil {
+		t.Error("expected error for --pre with minor")
+	}
+}
+
+// --- bump command tests ---
+
+func TestMain_BumpPrintsNextVersion(t *testing.T) {
+	dir := newTestRepo(t)
+	runIn(t, dir, "git", "tag", "v1.2.3")
+	runIn(t, dir, "git", "tag", "not-semver")
+	commitFile(t, dir, "a", "a", "fix: a")
+
+	out, err := runBinary(t, dir, "bump", "minor")
+	if err != nil {
+		t.Fatalf("bump failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Current version: v1.2.3") || !strings.Contains(out, "Next version:    v1.3.0") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if tags := runIn(t, dir, "git", "tag", "--list", "v1.3.0"); tags != "" {
+		t.Errorf("bump without --tag should not create a tag, found %q", tags)
+	}
+}
+
+func TestMain_BumpCreatesAnnotatedTag(t *testing.T) {
+	dir := newTestRepo(t)
+	runIn(t, dir, "git", "tag", "v0.4.0")
+	commitFile(t, dir, "a", "a", "feat: a")
+
+	out, err := runBinary(t, dir, "bump", "prerelease", "--pre", "rc", "--tag", "-m", "Candidate")
+	if err != nil {
+		t.Fatalf("bump failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Created tag v0.4.1-rc.1") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if typ := runIn(t, dir, "git", "cat-file", "-t", "v0.4.1-rc.1"); typ != "tag" {
+		t.Errorf("tag object type = %q, want annotated tag", typ)
+	}
+	if msg := runIn(t, dir, "git", "tag", "-l", "--format=%(contents)", "v0.4.1-rc.1"); msg != "Candidate" {
+		t.Errorf("tag message = %q, want %q", msg, "Candidate")
+	}
+}
+
+func TestMain_BumpDryRun(t *testing.T) {
+	dir := newTestRepo(t)
+
+	out, err := runBinary(t, dir, "bump", "patch", "--push", "--dry-run")
+	if err != nil {
+		t.Fatalf("bump failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Would create tag v0.0.1") || !strings.Contains(out, "Would push tag v0.0.1 to origin") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if tags := runIn(t, dir, "git", "tag"); tags != "" {
+		t.Errorf("dry run should not create tags, found %q", tags)
+	}
+}
+
+func TestMain_BumpPush(t *testing.T) {
+	dir := newTestRepo(t)
+	remote := t.TempDir()
+	runIn(t, remote, "git", "init", "-q", "--bare")
+	runIn(t, dir, "git", "remote", "add", "origin", remote)
+	runIn(t, dir, "git", "tag", "v1.0.0")
+	commitFile(t, dir, "a", "a", "fix: a")
+
+	out, err := runBinary(t, dir, "bump", "--push")
+	if err != nil {
+		t.Fatalf("bump failed: %v\n%s", err, out)
+	}
+	if tags := runIn(t, remote, "git", "tag"); tags != "v1.0.1" {
+		t.Errorf("remote tags = %q, want v1.0.1", tags)
+	}
+}
+
+func TestMain_BumpRejectsExtraArguments(t *testing.T) {
+	dir := newTestRepo(t)
+	for _, args := range [][]string{
+		{"bump", "--tag", "minor", "--push"},
+		{"bump", "minor", "--tag", "patch"},
+		{"bump", "minor", "--pre", "rc"},
+	} {
+		out, err := runBinary(t, dir, args...)
+		if err == nil {
+			t.Errorf("%q should fail:\n%s", args, out)
+		}
+	}
+	if tags := runIn(t, dir, "git", "tag"); tags != "" {
+		t.Errorf("rejected bumps should not create tags, found %q", tags)
+	}
+}
+
+func TestMain_BumpRefusesDirtyTree(t *testing.T) {
+	dir := newTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runBinary(t, dir, "bump")
+	if err == nil {
+		t.Fatalf("bump should fail on a dirty tree:\n%s", out)
+	}
+	if !strings.Contains(out, "uncommitted changes") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestMain_BumpRefusesTaggedHead(t *testing.T) {
+	dir := newTestRepo(t)
+	runIn(t, dir, "git", "tag", "v2.0.0")
+
+	out, err := runBinary(t, dir, "bump")
+	if err == nil {
+		t.Fatalf("bump should fail when HEAD is tagged:\n%s", out)
+	}
+	if !strings.Contains(out, "already tagged as v2.0.0") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
@@ -0,0 +1,370 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+const changelogUsage = `Generate a Keep a Changelog section from Conventional Commits
+
+Usage:
+  go-version changelog [options]
+
+Commits between two refs are grouped by Conventional Commit type into a
+Markdown section. Breaking changes are listed first and marked in their group.
+Commit hashes link to the repository when its URL can be derived.
+
+Options:
+      --from         Start ref, exclusive (default: latest SemVer tag before --to)
+      --to           End ref, inclusive (default: HEAD)
+      --version      Section version (default: --to if it is a SemVer tag, else Unreleased)
+      --date         Section date (default: date of the --to commit)
+      --repo         Repository URL for links (default: git remote get-url origin)
+      --all          Include commits of other types and non-conventional commits
+      --prepend      Insert the section into this Markdown file (e.g. CHANGELOG.md)
+      --format       Output format: markdown or go (default: markdown)
+      --package      Package name for --format go (default: main)
+  -o, --output       Write to a file instead of stdout
+
+The go format writes a Go source file whose init function calls
+version.SetChangelog, so App().Changelog is populated at build time.
+
+Examples:
+  go-version changelog                                        # Unreleased changes since the latest tag
+  go-version changelog --version v1.3.0 --prepend CHANGELOG.md
+  go-version changelog --from v1.1.0 --to v1.2.0
+  go-version changelog --format go -o changelog_gen.go        # Compile into the binary
+`
+
+// unreleasedHeading heads the section of changes without a version. It is
+// replaced, not duplicated, when a section is prepended.
+const unreleasedHeading = "## [Unreleased]"
+
+// linkDefinition matches a Markdown link reference definition such as
+// "[1.2.0]: https://github.com/org/app/compare/v1.1.0...v1.2.0".
+var linkDefinition = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+
+// changelogGroups lists the section headings in output order with the
+// Conventional Commit types they collect.
+var changelogGroups = []struct {
+	title string
+	types []string
+}{
+	{"Added", []string{"feat"}},
+	{"Fixed", []string{"fix"}},
+	{"Changed", []string{"perf", "refactor"}},
+	{"Removed", []string{"revert"}},
+	{"Security", []string{"security"}},
+}
+
+// changelogSection holds what is needed to render one release section.
+type changelogSection struct {
+	Version string
+	Date    string
+	RepoURL string
+	Commits []commit
+	All     bool
+}
+
+func cmdChangelog(args []string) {
+	fs := flag.NewFlagSet("changelog", flag.ExitOnError)
+	fs.Usage = func() { _, _ = os.Stdout.WriteString(changelogUsage) }
+
+	var from, to, ver, date, repo, prepend, format, pkg, output string
+	var all bool
+	fs.StringVar(&from, "from", "", "Start ref (exclusive)")
+	fs.StringVar(&to, "to", "HEAD", "End ref (inclusive)")
+	fs.StringVar(&ver, "version", "", "Section version")
+	fs.StringVar(&date, "date", "", "Section date")
+	fs.StringVar(&repo, "repo", "", "Repository URL")
+	fs.BoolVar(&all, "all", false, "Include all commits")
+	fs.StringVar(&prepend, "prepend", "", "Markdown file to insert the section into")
+	fs.StringVar(&format, "format", "markdown", "Output format (markdown, go)")
+	fs.StringVar(&pkg, "package", "main", "Package name for --format go")
+	fs.StringVar(&output, "o", "", "Output file")
+	fs.StringVar(&output, "output", "", "Output file")
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+	if format != "markdown" && format != "go" {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q (want markdown or go)\n", format)
+		os.Exit(1)
+	}
+
+	if gitCommand("rev-parse", "--git-dir") == "" {
+		fmt.Fprintln(os.Stderr, "Error: not a git repository")
+		os.Exit(1)
+	}
+	for _, ref := range []string{from, to} {
+		if ref != "" && gitCommand("rev-parse", "--verify", "--quiet", ref+"^{commit}") == "" {
+			fmt.Fprintf(os.Stderr, "Error: unknown ref %q\n", ref)
+			os.Exit(1)
+		}
+	}
+
+	// A SemVer tag at --to names the section and is excluded when looking
+	// for the previous release.
+	if ver == "" {
+		if v, err := version.Parse(to); err == nil {
+			ver = v.Raw
+		}
+	}
+	revRange := to
+	if from == "" {
+		tags := semverTags(gitLines("tag", "--merged", to))
+		for i := len(tags) - 1; i >= 0; i-- {
+			if tags[i].Raw != to {
+				from = tags[i].Raw
+				break
+			}
+		}
+	}
+	if from != "" {
+		revRange = from + ".." + to
+	}
+
+	if date == "" {
+		if ts := gitCommand("log", "-1", "--format=%cI", to); len(ts) >= 10 {
+			date = ts[:10]
+		} else {
+			date = time.Now().UTC().Format("2006-01-02")
+		}
+	}
+	if repo == "" {
+		repo = gitCommand("remote", "get-url", "origin")
+	}
+
+	section := renderChangelog(changelogSection{
+		Version: ver,
+		Date:    date,
+		RepoURL: webURL(repo),
+		Commits: gitLog(revRange),
+		All:     all,
+	})
+
+	if prepend != "" {
+		if err := prependChangelog(prepend, section); err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating %s: %v\n", prepend, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Updated %s\n", prepend)
+		return
+	}
+
+	content := section
+	if format == "go" {
+		content = changelogGoSource(pkg, section)
+	}
+	if output == "" {
+		fmt.Print(content)
+		return
+	}
+	if err := os.WriteFile(output, []byte(content), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Generated %s\n", output)
+}
+
+// renderChangelog renders a Keep a Changelog section.
+func renderChangelog(s changelogSection) string {
+	var sb strings.Builder
+	if s.Version == "" {
+		sb.WriteString(unreleasedHeading + "\n")
+	} else {
+		fmt.Fprintf(&sb, "## [%s] - %s\n", strings.TrimPrefix(s.Version, "v"), s.Date)
+	}
+
+	var breaking []commit
+	grouped := map[string][]commit{}
+	var other []commit
+	for _, c := range s.Commits {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+		if title := groupTitle(c.Type); title != "" {
+			grouped[title] = append(grouped[title], c)
+		} else if s.All || c.Breaking {
+			other = append(other, c)
+		}
+	}
+
+	if len(breaking) > 0 {
+		sb.WriteString("\n### Breaking Changes\n\n")
+		for _, c := range breaking {
+			fmt.Fprintf(&sb, "- %s%s%s\n", scopePrefix(c), c.BreakingNote, commitLink(c, s.RepoURL))
+		}
+	}
+	for _, g := range changelogGroups {
+		if len(grouped[g.title]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n### %s\n\n", g.title)
+		for _, c := range grouped[g.title] {
+			sb.WriteString(changelogEntry(c, s.RepoURL))
+		}
+	}
+	if len(other) > 0 {
+		sb.WriteString("\n### Other\n\n")
+		for _, c := range other {
+			sb.WriteString(changelogEntry(c, s.RepoURL))
+		}
+	}
+	return sb.String()
+}
+
+func groupTitle(typ string) string {
+	for _, g := range changelogGroups {
+		for _, t := range g.types {
+			if t == typ {
+				return g.title
+			}
+		}
+	}
+	return ""
+}
+
+func changelogEntry(c commit, repoURL string) string {
+	desc := c.Description
+	if c.Type == "" {
+		desc = c.Subject
+	}
+	marker := ""
+	if c.Breaking {
+		marker = "**BREAKING** "
+	}
+	return fmt.Sprintf("- %s%s%s%s\n", marker, scopePrefix(c), desc, commitLink(c, repoURL))
+}
+
+func scopePrefix(c commit) string {
+	if c.Scope == "" {
+		return ""
+	}
+	return "**" + c.Scope + ":** "
+}
+
+// commitLink renders the short hash, linked when the repository URL is known.
+func commitLink(c commit, repoURL string) string {
+	short := c.Hash
+	if len(short) > 7 {
+		short = short[:7]
+	}
+	if repoURL == "" {
+		return fmt.Sprintf(" (%s)", short)
+	}
+	return fmt.Sprintf(" ([%s](%s/commit/%s))", short, repoURL, c.Hash)
+}
+
+// webURL converts a git remote URL into a browsable https URL, e.g.
+// git@github.com:user/repo.git -> https://github.com/user/repo.
+// Credentials are stripped. It returns "" for local paths.
+func webURL(remote string) string {
+	u := strings.TrimSpace(remote)
+	u = strings.TrimSuffix(strings.TrimSuffix(u, "/"), ".git")
+	switch {
+	case strings.HasPrefix(u, "https://"), strings.HasPrefix(u, "http://"):
+		scheme := u[:strings.Index(u, "://")+3]
+		rest := u[len(scheme):]
+		if at := strings.Index(rest, "@"); at >= 0 && at < strings.Index(rest+"/", "/") {
+			rest = rest[at+1:]
+		}
+		return scheme + rest
+	case strings.HasPrefix(u, "ssh://"):
+		rest := strings.TrimPrefix(u, "ssh://")
+		if at := strings.Index(rest, "@"); at >= 0 {
+			rest = rest[at+1:]
+		}
+		// drop an explicit port
+		if slash := strings.Index(rest, "/"); slash >= 0 {
+			host := rest[:slash]
+			if colon := strings.Index(host, ":"); colon >= 0 {
+				host = host[:colon]
+			}
+			rest = host + rest[slash:]
+		}
+		return "https://" + rest
+	case strings.Contains(u, "@") && strings.Contains(u, ":"):
+		// scp-like syntax: user@host:path
+		rest := u[strings.Index(u, "@")+1:]
+		return "https://" + strings.Replace(rest, ":", "/", 1)
+	}
+	return ""
+}
+
+// prependChangelog inserts section before the first release heading of the
+// Markdown file at path, creating the file if it does not exist. An existing
+// Unreleased section is replaced by the new section, whether it is another
+// Unreleased section or the release of those changes.
+func prependChangelog(path, section string) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- user-specified changelog file
+	if os.IsNotExist(err) {
+		data = []byte("# Changelog\n\nAll notable changes to this project will be documented in this file.\n")
+	} else if err != nil {
+		return err
+	}
+
+	heading := strings.SplitN(section, "\n", 2)[0]
+	lines := strings.Split(string(data), "\n")
+	insert, end := len(lines), -1
+	for i, line := range lines {
+		if line == heading && heading != unreleasedHeading {
+			return fmt.Errorf("section %q already exists", heading)
+		}
+		if line == unreleasedHeading && end < 0 {
+			// the Unreleased section is regenerated or released, replace it
+			insert, end = i, sectionEnd(lines, i)
+			continue
+		}
+		if strings.HasPrefix(line, "## ") && insert == len(lines) {
+			insert = i
+		}
+	}
+	if end < 0 {
+		end = insert
+	}
+
+	var out []string
+	out = append(out, lines[:insert]...)
+	if insert > 0 && out[len(out)-1] != "" {
+		out = append(out, "")
+	}
+	out = append(out, strings.Split(strings.TrimRight(section, "\n"), "\n")...)
+	out = append(out, "")
+	out = append(out, lines[end:]...)
+	content := strings.TrimRight(strings.Join(out, "\n"), "\n") + "\n"
+	return os.WriteFile(path, []byte(content), 0600)
+}
+
+// sectionEnd returns the index of the line after the section starting at
+// start: the next "## " heading or the link reference definitions at the end
+// of the file.
+func sectionEnd(lines []string, start int) int {
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "## ") || linkDefinition.MatchString(lines[i]) {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// changelogGoSource renders a Go file that sets the changelog at init time.
+func changelogGoSource(pkg, changelog string) string {
+	return fmt.Sprintf(`// Code generated by go-version changelog. DO NOT EDIT.
+
+package %s
+
+import version "github.com/rbaliyan/go-version"
+
+func init() {
+	version.SetChangelog(%q)
+}
+`, pkg, changelog)
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+// bumpLevel is the part of a version a change requires bumping.
+type bumpLevel int
+
+const (
+	bumpNone bumpLevel = iota
+	bumpPatch
+	bumpMinor
+	bumpMajor
+)
+
+func (l bumpLevel) String() string {
+	switch l {
+	case bumpPatch:
+		return "patch"
+	case bumpMinor:
+		return "minor"
+	case bumpMajor:
+		return "major"
+	}
+	return "none"
+}
+
+// MarshalText encodes the level by name for JSON output.
+func (l bumpLevel) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func parseBumpLevel(s string) (bumpLevel, error) {
+	switch s {
+	case "none":
+		return bumpNone, nil
+	case "patch":
+		return bumpPatch, nil
+	case "minor":
+		return bumpMinor, nil
+	case "major":
+		return bumpMajor, nil
+	}
+	return bumpNone, fmt.Errorf("unknown bump level %q (want major, minor, patch or none)", s)
+}
+
+// defaultTypeBumps maps Conventional Commit types to the bump they trigger.
+// Types not listed (docs, ci, chore, ...) do not trigger a release.
+var defaultTypeBumps = map[string]bumpLevel{
+	"feat": bumpMinor,
+	"fix":  bumpPatch,
+	"perf": bumpPatch,
+}
+
+// commit is a git commit, parsed as a Conventional Commit if possible.
+type commit struct {
+	Hash    string `json:"hash"`
+	Subject string `json:"subject"`
+	Body    string `json:"-"`
+
+	// Conventional Commit fields, Type is empty for non-conventional commits
+	Type        string `json:"type,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+	Description string `json:"description,omitempty"`
+	Breaking    bool   `json:"breaking,omitempty"`
+	// text of a BREAKING CHANGE footer, or the description for "type!:" headers
+	BreakingNote string `json:"breaking_note,omitempty"`
+}
+
+var headerPattern = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]*)\))?(!)?: +(.+)$`)
+
+var breakingFooterPattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: *(.*)$`)
+
+// parseCommit parses the subject and body of a commit.
+func parseCommit(hash, subject, body string) commit {
+	c := commit{Hash: hash, Subject: subject, Body: body}
+	m := headerPattern.FindStringSubmatch(subject)
+	if m == nil {
+		return c
+	}
+	c.Type = strings.ToLower(m[1])
+	c.Scope = m[2]
+	c.Description = m[4]
+	if m[3] == "!" {
+		c.Breaking = true
+		c.BreakingNote = c.Description
+	}
+	if f := breakingFooterPattern.FindStringSubmatch(body); f != nil {
+		c.Breaking = true
+		if f[1] != "" {
+			c.BreakingNote = strings.TrimSpace(f[1])
+		}
+	}
+	return c
+}
+
+// bumpFor returns the bump a commit triggers under the given type mapping.
+// Breaking changes bump the major version, or the minor version while the
+// major version is 0.
+func bumpFor(c commit, types map[string]bumpLevel, current version.Version) bumpLevel {
+	if c.Type == "" {
+		return bumpNone
+	}
+	if c.Breaking {
+		if current.Major == 0 {
+			return bumpMinor
+		}
+		return bumpMajor
+	}
+	return types[c.Type]
+}
+
+// fieldSeparator and commitSeparator delimit records in gitLog output,
+// matching the %x1f and %x1e placeholders in gitLogFormat.
+const (
+	fieldSeparator  = "\x1f"
+	commitSeparator = "\x1e"
+	gitLogFormat    = "--format=%H%x1f%s%x1f%b%x1e"
+)
+
+// gitLog returns the commits in revRange (e.g. "v1.2.3..HEAD"), newest first.
+func gitLog(revRange string) []commit {
+	out := gitCommand("log", gitLogFormat, revRange)
+	var commits []commit
+	for _, rec := range strings.Split(out, commitSeparator) {
+		rec = strings.TrimSpace(rec)
+		if rec == "" {
+			continue
+		}
+		fields := strings.SplitN(rec, fieldSeparator, 3)
+		if len(fields) < 2 {
+			continue
+		}
+		body := ""
+		if len(fields) == 3 {
+			body = strings.TrimSpace(fields[2])
+		}
+		commits = append(commits, parseCommit(fields[0], fields[1], body))
+	}
+	return commits
+}
+
+// sinceLatestTag returns the latest SemVer tag reachable from HEAD and the
+// revision range of the commits after it. Without tags the range covers the
+// whole history.
+func sinceLatestTag() (version.Version, string) {
+	tags := semverTags(gitLines("tag", "--merged", "HEAD"))
+	if len(tags) == 0 {
+		return latestTag(nil), "HEAD"
+	}
+	latest := tags[len(tags)-1]
+	return latest, latest.Raw + "..HEAD"
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"debug/buildinfo"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+const inspectUsage = `Show the version information compiled into a Go binary
+
+Usage:
+  go-version inspect [options] <binary>
+
+Reads the variables injected with -ldflags -X (VersionInfo, GitCommit, ...)
+and the module version and VCS settings the go command records, preferring
+the ldflags values as the library does at init. Binaries built with -trimpath
+do not record -ldflags, only the VCS settings are available for them.
+
+Options:
+  -p, --package      Package path of the variables, as given to ldflags -p
+                     (default: github.com/rbaliyan/go-version)
+` + outputOptionsUsage + `
+Examples:
+  go-version inspect ./bin/myapp
+  go-version inspect --format json ./bin/myapp
+  go-version inspect --template '{{.Commit}}' ./bin/myapp
+`
+
+func cmdInspect(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	fs.Usage = func() { _, _ = os.Stdout.WriteString(inspectUsage) }
+	var pkg string
+	fs.StringVar(&pkg, "p", defaultPackage, "Package path")
+	fs.StringVar(&pkg, "package", defaultPackage, "Package path")
+	var out outputOptions
+	out.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+	if err := out.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Error: expected one binary to inspect")
+		os.Exit(1)
+	}
+
+	bi, err := buildinfo.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+	m := inspectInfo(bi, pkg).Snapshot()
+	f := m.File()
+	err = out.write(os.Stdout, m, func(w io.Writer) {
+		fmt.Fprintf(w, "Path:     %s\n", valueOrNA(bi.Path))
+		fmt.Fprintf(w, "Module:   %s\n", valueOrNA(strings.TrimSpace(bi.Main.Path+" "+bi.Main.Version)))
+		fmt.Fprintf(w, "Go:       %s\n", bi.GoVersion)
+		fmt.Fprintf(w, "Version:  %s\n", valueOrNA(m.Version.Raw))
+		fmt.Fprintf(w, "Commit:   %s\n", valueOrNA(f.Commit))
+		fmt.Fprintf(w, "Branch:   %s\n", valueOrNA(f.Branch))
+		fmt.Fprintf(w, "Repo:     %s\n", valueOrNA(f.Repo))
+		fmt.Fprintf(w, "Dirty:    %t\n", f.Dirty == "true")
+		fmt.Fprintf(w, "Built:    %s\n", valueOrNA(f.BuildTimestamp))
+		if len(f.Extra) > 0 {
+			fmt.Fprintf(w, "Extra:    %s\n", extraText(f.Extra))
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// inspectInfo resolves the version information of a binary from the -ldflags
+// -X values of package pkg, then its module version and VCS settings.
+func inspectInfo(bi *debug.BuildInfo, pkg string) *version.Info {
+	ldflags := version.Partial{Source: version.SourceLdflags}
+	vcs := version.Partial{Source: version.SourceBuildInfo}
+	if v := bi.Main.Version; v != "" && v != "(devel)" {
+		vcs.Version = v
+	}
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "-ldflags":
+			ldflags = version.LdflagsPartial(ldflagsValues(s.Value, pkg))
+		case "vcs.revision":
+			vcs.Commit = s.Value
+		case "vcs.time":
+			vcs.Timestamp = s.Value
+		case "vcs.modified":
+			vcs.Dirty = s.Value
+		}
+	}
+
+	info := version.New()
+	_ = info.ResolveContext(context.Background(),
+		version.ProviderFunc(func(context.Context) (version.Partial, error) { return ldflags, nil }),
+		version.ProviderFunc(func(context.Context) (version.Partial, error) { return vcs, nil }),
+	)
+	return info
+}
+
+// ldflagsValues returns the variables of package pkg set with -X in a
+// -ldflags value, keyed by variable name without the package path.
+func ldflagsValues(ldflags, pkg string) map[string]string {
+	values := map[string]string{}
+	fields := splitQuoted(ldflags)
+	for i := 0; i < len(fields); i++ {
+		var def string
+		switch f := fields[i]; {
+		case f == "-X" || f == "--X":
+			if i+1 < len(fields) {
+				i++
+				def = fields[i]
+			}
+		case strings.HasPrefix(f, "-X="):
+			def = strings.TrimPrefix(f, "-X=")
+		case strings.HasPrefix(f, "--X="):
+			def = strings.TrimPrefix(f, "--X=")
+		}
+		name, value, ok := strings.Cut(def, "=")
+		if !ok {
+			continue
+		}
+		dot := strings.LastIndex(name, ".")
+		if dot < 0 || name[:dot] != pkg {
+			continue
+		}
+		values[name[dot+1:]] = value
+	}
+	return values
+}
+
+// splitQuoted splits s into fields at spaces, where a field may be wrapped
+// in single or double quotes, as the go command splits -ldflags.
+func splitQuoted(s string) []string {
+	var fields []string
+	for {
+		s = strings.TrimLeft(s, " \t\n\r")
+		if s == "" {
+			return fields
+		}
+		if q := s[0]; q == '\'' || q == '"' {
+			if end := strings.IndexByte(s[1:], q); end >= 0 {
+				fields = append(fields, s[1:end+1])
+				s = s[end+2:]
+				continue
+			}
+		}
+		end := strings.IndexAny(s, " \t\n\r")
+		if end < 0 {
+			return append(fields, s)
+		}
+		fields = append(fields, s[:end])
+		s = s[end:]
+	}
+}
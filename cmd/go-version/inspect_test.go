@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestSplitQuoted(t *testing.T) {
+	tests := map[string][]string{
+		"":                            nil,
+		"-s -w":                       {"-s", "-w"},
+		`-X 'a.B=x y'  -X "c.D=it's"`: {"-X", "a.B=x y", "-X", "c.D=it's"},
+		"-X a.B=it's":                 {"-X", "a.B=it's"},
+		"'unterminated":               {"'unterminated"},
+	}
+	for in, want := range tests {
+		if got := splitQuoted(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitQuoted(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLdflagsValues(t *testing.T) {
+	ldflags := `-s -w -X 'github.com/rbaliyan/go-version.VersionInfo=v1.2.3' -X=github.com/rbaliyan/go-version.ExtraInfo=a=b -X main.GitCommit=abc -X other/go-version.GitBranch=dev -X`
+	got := ldflagsValues(ldflags, defaultPackage)
+	want := map[string]string{"VersionInfo": "v1.2.3", "ExtraInfo": "a=b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ldflagsValues() = %v, want %v", got, want)
+	}
+	got = ldflagsValues(ldflags, "main")
+	if want := map[string]string{"GitCommit": "abc"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ldflagsValues(main) = %v, want %v", got, want)
+	}
+}
+
+func TestInspectInfo(t *testing.T) {
+	bi := &debug.BuildInfo{
+		Path: "example.com/app",
+		Main: debug.Module{Path: "example.com/app", Version: "v1.0.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "-ldflags", Value: `-X 'example.com/app/version.VersionInfo=v1.2.3' -X example.com/app/version.GitBranch=main -X 'example.com/app/version.ExtraInfo=channel=beta&pipeline=12+34' -X main.GitCommit=def456`},
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.time", Value: "2024-01-15T10:30:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	f := inspectInfo(bi, "example.com/app/version").Snapshot().File()
+	if f.Version != "1.2.3" || f.Branch != "main" || f.Commit != "abc123" || f.Dirty != "true" {
+		t.Errorf("ldflags should win and VCS settings fill the rest, got %+v", f)
+	}
+	if f.BuildTimestamp != "2024-01-15T10:30:00Z" {
+		t.Errorf("BuildTimestamp = %q", f.BuildTimestamp)
+	}
+	if want := map[string]string{"channel": "beta", "pipeline": "12 34"}; !reflect.DeepEqual(f.Extra, want) {
+		t.Errorf("Extra = %v, want %v", f.Extra, want)
+	}
+
+	// -trimpath builds record no -ldflags
+	bi.Settings = bi.Settings[1:]
+	if f := inspectInfo(bi, "example.com/app/version").Snapshot().File(); f.Version != "1.0.0" {
+		t.Errorf("Version = %q, want the module version", f.Version)
+	}
+}
+
+func TestMain_Inspect(t *testing.T) {
+	binary := buildTestBinary(t)
+	out, err := runBinary(t, t.TempDir(), "inspect", "--format", "json", binary)
+	if err != nil {
+		t.Fatalf("inspect failed: %v\n%s", err, out)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("inspect --format json is not JSON: %v\n%s", err, out)
+	}
+
+	out, err = runBinary(t, t.TempDir(), "inspect", binary)
+	if err != nil || !strings.Contains(out, "Path:     github.com/rbaliyan/go-version/cmd/go-version") {
+		t.Errorf("inspect text output: %v\n%s", err, out)
+	}
+
+	if out, err := runBinary(t, t.TempDir(), "inspect", "missing-binary"); err == nil {
+		t.Errorf("inspect of a missing file should fail:\n%s", out)
+	}
+}
@@ -1,12 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
-	"time"
 
 	version "github.com/rbaliyan/go-version"
 )
@@ -20,38 +21,80 @@ Usage:
   go-version <command> [options]
 
 Commands:
+  bump        Compute the next version from the latest tag and optionally tag it
+  changelog   Generate a Keep a Changelog section from Conventional Commits
   file        Generate a .version file from git or manual input
+  inspect     Show the version information compiled into a Go binary
   ldflags     Generate go build command with -ldflags for version injection
+  next        Infer the next version from Conventional Commits since the latest tag
   show        Show version information from git
   version     Show go-version CLI version
 
 Run 'go-version <command> -h' for more information on a command.
 `
 
-const fileUsage = `Generate a .version file
+const fileUsage = `Generate a version file
 
 Usage:
   go-version file [options]
 
 Options:
-  -o, --output       Output file path (default: .version)
+  -o, --output       Output file path (default: .version, or .version.<format>)
+  -f, --format       File format: env, json, yaml or toml (default: from the
+                     output extension, else env)
   -v, --version      Version string (default: from git describe)
   -t, --timestamp    Build timestamp (default: current time)
       --timeformat   Timestamp format for date command (default: "%%Y-%%m-%%dT%%H:%%M:%%SZ")
+      --name         Application name
+      --description  Application description
+      --set          Extra label key=value, repeatable
+
+The library loads every format and detects it from the extension (.json,
+.yaml, .yml, .toml, anything else is env). See the File type for the schema.
 
 Examples:
   go-version file                                                     # Generate from git
   go-version file -o build/.version                                   # Custom output path
   go-version file -v 1.2.3                                            # Manual version
+  go-version file --format json --name myapp                          # .version.json
+  go-version file -o deploy/version.yaml                              # YAML, from the extension
   go-version file --timeformat "%%a %%b %%d %%H:%%M:%%S %%Z %%Y"     # UnixDate format
+
+Embedding:
+  As an alternative to ldflags, generate the file next to your main package
+  and compile it into the binary, so images that ship only the binary keep it:
+
+    go-version file -o cmd/myapp/.version
+
+    //go:embed .version
+    var versionFile []byte
+
+    func main() {
+        version.LoadFromBytes(versionFile)
+    }
 `
 
 const showUsage = `Show version information from git
 
 Usage:
+  go-version show [options]
+
+Options:
+` + outputOptionsUsage + `
+Examples:
   go-version show
+  go-version show --format json
+  go-version show --template '{{.Commit}}'
 `
 
+const versionUsage = `Show go-version CLI version
+
+Usage:
+  go-version version [options]
+
+Options:
+` + outputOptionsUsage
+
 const ldflagsUsage = `Generate -ldflags value for version injection
 
 Usage:
@@ -64,12 +107,17 @@ Options:
       --timeformat   Timestamp format for date command (default: "%%Y-%%m-%%dT%%H:%%M:%%SZ")
       --shell        Output shell command with $() substitutions (default)
       --static       Output static values instead of shell substitutions
+      --set          Extra label key=value, repeatable (injected as ExtraInfo)
+      --dirty        Append -dirty to the version if tracked files have uncommitted changes
+      --fail-if-dirty  Exit with an error if tracked files have uncommitted changes
 
 Examples:
   go build -ldflags="$(go-version ldflags)" ./cmd/myapp                        # Without timestamp
   go build -ldflags="$(go-version ldflags -t)" ./cmd/myapp                     # With timestamp (RFC 3339)
   go build -ldflags="$(go-version ldflags -t --timeformat '%%Y-%%m-%%d')" ./cmd/myapp
   go build -ldflags="$(go-version ldflags --static)" ./cmd/myapp               # Static values for CI
+  go build -ldflags="$(go-version ldflags --set pipeline=$CI_PIPELINE_ID --set channel=beta)" ./cmd/myapp
+  go build -ldflags="$(go-version ldflags --static --fail-if-dirty)" ./cmd/myapp  # Release builds
 `
 
 func main() {
@@ -79,14 +127,22 @@ func main() {
 	}
 
 	switch os.Args[1] {
+	case "bump":
+		cmdBump(os.Args[2:])
+	case "changelog":
+		cmdChangelog(os.Args[2:])
 	case "file":
 		cmdFile(os.Args[2:])
+	case "inspect":
+		cmdInspect(os.Args[2:])
 	case "ldflags":
 		cmdLdflags(os.Args[2:])
+	case "next":
+		cmdNext(os.Args[2:])
 	case "show":
 		cmdShow(os.Args[2:])
 	case "version", "-v", "--version":
-		cmdVersion()
+		cmdVersion(os.Args[2:])
 	case "-h", "--help", "help":
 		fmt.Print(usage)
 	default:
@@ -100,28 +156,36 @@ func cmdFile(args []string) {
 	fs := flag.NewFlagSet("file", flag.ExitOnError)
 	fs.Usage = func() { _, _ = os.Stdout.WriteString(fileUsage) }
 
-	var output, ver, timestamp, timeformat string
-	fs.StringVar(&output, "o", ".version", "Output file path")
-	fs.StringVar(&output, "output", ".version", "Output file path")
+	var output, format, ver, timestamp, timeformat, name, description string
+	fs.StringVar(&output, "o", "", "Output file path")
+	fs.StringVar(&output, "output", "", "Output file path")
+	fs.StringVar(&format, "f", "", "File format (env, json, yaml, toml)")
+	fs.StringVar(&format, "format", "", "File format (env, json, yaml, toml)")
 	fs.StringVar(&ver, "v", "", "Version string")
 	fs.StringVar(&ver, "version", "", "Version string")
 	fs.StringVar(&timestamp, "t", "", "Build timestamp")
 	fs.StringVar(&timestamp, "timestamp", "", "Build timestamp")
 	fs.StringVar(&timeformat, "timeformat", defaultDateFormat, "Timestamp format (date command format)")
+	fs.StringVar(&name, "name", "", "Application name")
+	fs.StringVar(&description, "description", "", "Application description")
+	extra := labelsFlag{}
+	fs.Var(extra, "set", "Extra label key=value (repeatable)")
 
 	if err := fs.Parse(args); err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Gather git info
-	commit := gitCommand("rev-parse", "HEAD")
-	branch := gitCommand("rev-parse", "--abbrev-ref", "HEAD")
-	repo := gitCommand("remote", "get-url", "origin")
+	fileFormat, output, err := fileTarget(format, output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
+	git := gitInfo()
 	// Use git describe for version if not provided
 	if ver == "" {
-		ver = gitCommand("describe", "--tags", "--always")
+		ver = git.Version
 	}
 
 	// Use current time if timestamp not provided
@@ -129,25 +193,24 @@ func cmdFile(args []string) {
 		timestamp = dateCommand(timeformat)
 	}
 
-	// Build file content
-	var sb strings.Builder
-	sb.WriteString("# Generated by go-version\n")
-	if ver != "" {
-		sb.WriteString(fmt.Sprintf("VERSION=%s\n", ver))
-	}
-	if commit != "" {
-		sb.WriteString(fmt.Sprintf("GIT_COMMIT=%s\n", commit))
-	}
-	if branch != "" {
-		sb.WriteString(fmt.Sprintf("GIT_BRANCH=%s\n", branch))
-	}
-	if repo != "" {
-		sb.WriteString(fmt.Sprintf("GIT_REPO=%s\n", repo))
+	content, err := version.File{
+		AppName:        name,
+		AppDescription: description,
+		Version:        ver,
+		Commit:         git.Commit,
+		Branch:         git.Branch,
+		Repo:           git.Repo,
+		Dirty:          git.Dirty,
+		BuildTimestamp: timestamp,
+		Extra:          extra,
+	}.Encode(fileFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
-	sb.WriteString(fmt.Sprintf("BUILD_TIMESTAMP=%s\n", timestamp))
 
 	// Write to file
-	if err := os.WriteFile(output, []byte(sb.String()), 0600); err != nil {
+	if err := os.WriteFile(output, content, 0600); err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
 		os.Exit(1)
 	}
@@ -155,25 +218,96 @@ func cmdFile(args []string) {
 	fmt.Printf("Generated %s\n", output)
 }
 
+// labelsFlag collects repeated key=value extra labels.
+type labelsFlag map[string]string
+
+func (l labelsFlag) String() string {
+	return l.encode()
+}
+
+func (l labelsFlag) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	l[parts[0]] = parts[1]
+	return nil
+}
+
+// encode returns the labels URL-encoded as read from the ExtraInfo variable.
+func (l labelsFlag) encode() string {
+	return version.EncodeExtraInfo(l)
+}
+
+// fileTarget resolves the format and output path of the file command. An
+// explicit format wins over the output extension; without an output path
+// the file is .version, with the format as extension unless it is env.
+func fileTarget(format, output string) (version.Format, string, error) {
+	f := version.FormatOf(output)
+	if format != "" {
+		var err error
+		if f, err = version.ParseFormat(format); err != nil {
+			return "", "", err
+		}
+	}
+	if output == "" {
+		output = ".version"
+		if f != version.FormatEnv {
+			output += "." + string(f)
+		}
+	}
+	return f, output, nil
+}
+
 func cmdShow(args []string) {
 	fs := flag.NewFlagSet("show", flag.ExitOnError)
-	fs.Usage = func() { fmt.Print(showUsage) }
+	fs.Usage = func() { _, _ = os.Stdout.WriteString(showUsage) }
+	var out outputOptions
+	out.register(fs)
 	if err := fs.Parse(args); err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
 		os.Exit(1)
 	}
+	if err := out.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	commit := gitCommand("rev-parse", "HEAD")
-	branch := gitCommand("rev-parse", "--abbrev-ref", "HEAD")
-	repo := gitCommand("remote", "get-url", "origin")
-	version := gitCommand("describe", "--tags", "--always")
+	git := gitInfo()
+	info := version.New()
+	// a version that is not semantic, e.g. a bare commit name, fails to
+	// parse and is kept in Raw
+	_ = info.Resolve(version.ProviderFunc(func(context.Context) (version.Partial, error) { return git, nil }))
+	m := info.Snapshot()
+	f := m.File()
+	err := out.write(os.Stdout, m, func(w io.Writer) {
+		fmt.Fprintf(w, "Version:  %s\n", valueOrNA(git.Version))
+		fmt.Fprintf(w, "Commit:   %s\n", valueOrNA(f.Commit))
+		fmt.Fprintf(w, "Branch:   %s\n", valueOrNA(f.Branch))
+		fmt.Fprintf(w, "Repo:     %s\n", valueOrNA(f.Repo))
+		fmt.Fprintf(w, "Dirty:    %t\n", f.Dirty == "true")
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-	fmt.Printf("Version:  %s\n", valueOrNA(version))
-	fmt.Printf("Commit:   %s\n", valueOrNA(commit))
-	fmt.Printf("Branch:   %s\n", valueOrNA(branch))
-	fmt.Printf("Repo:     %s\n", valueOrNA(repo))
+// gitInfo reads the repository in the working directory, without running
+// git where possible, see version.GitProvider.
+func gitInfo() version.Partial {
+	p, _ := version.GitProvider().Load(context.Background())
+	return p
 }
 
+// defaultPackage is the import path of the package whose variables ldflags
+// sets and inspect reads.
+const defaultPackage = "github.com/rbaliyan/go-version"
+
+// dirtyStatusArgs lists uncommitted changes to tracked files, the same
+// changes git describe --dirty reports.
+var dirtyStatusArgs = []string{"status", "--porcelain", "--untracked-files=no"}
+
 func gitCommand(args ...string) string {
 	cmd := exec.Command("git", args...) // #nosec G204 -- all callers pass hardcoded git subcommands
 	out, err := cmd.Output()
@@ -198,21 +332,38 @@ func valueOrNA(s string) string {
 	return s
 }
 
-func cmdVersion() {
-	version.SetAppInfo("go-version", "Generate and manage version files for Go projects")
-	v := version.Get()
-	g := version.Git()
-	b := version.Build()
-
-	fmt.Printf("go-version %s\n", v.Raw)
-	if g.Commit != "" {
-		fmt.Printf("  commit:  %s\n", g.Commit)
+func cmdVersion(args []string) {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	fs.Usage = func() { _, _ = os.Stdout.WriteString(versionUsage) }
+	var out outputOptions
+	out.register(fs)
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		os.Exit(1)
 	}
-	if g.Branch != "" {
-		fmt.Printf("  branch:  %s\n", g.Branch)
+	if err := out.validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
-	if !b.Timestamp.IsZero() {
-		fmt.Printf("  built:   %s\n", b.Timestamp.Format(time.RFC3339))
+
+	version.SetAppInfo("go-version", "Generate and manage version files for Go projects")
+	m := version.Snapshot()
+	f := m.File()
+	err := out.write(os.Stdout, m, func(w io.Writer) {
+		fmt.Fprintf(w, "go-version %s\n", m.Version.Raw)
+		if f.Commit != "" {
+			fmt.Fprintf(w, "  commit:  %s\n", f.Commit)
+		}
+		if f.Branch != "" {
+			fmt.Fprintf(w, "  branch:  %s\n", f.Branch)
+		}
+		if f.BuildTimestamp != "" {
+			fmt.Fprintf(w, "  built:   %s\n", f.BuildTimestamp)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 }
 
@@ -230,27 +381,42 @@ func cmdLdflags(args []string) {
 	fs.BoolVar(&timestamp, "t", false, "Include build timestamp")
 	fs.BoolVar(&timestamp, "timestamp", false, "Include build timestamp")
 	fs.StringVar(&timeformat, "timeformat", defaultDateFormat, "Timestamp format (date command format)")
+	extra := labelsFlag{}
+	fs.Var(extra, "set", "Extra label key=value (repeatable)")
+	var dirtySuffix, failIfDirty bool
+	fs.BoolVar(&dirtySuffix, "dirty", false, "Append -dirty to a dirty version")
+	fs.BoolVar(&failIfDirty, "fail-if-dirty", false, "Fail on a dirty working tree")
 
 	if err := fs.Parse(args); err != nil {
 		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
 		os.Exit(1)
 	}
 
+	if failIfDirty {
+		if changes := gitCommand(dirtyStatusArgs...); changes != "" {
+			fmt.Fprintf(os.Stderr, "Error: working tree has uncommitted changes:\n%s\n", changes)
+			os.Exit(1)
+		}
+	}
+
 	// Use go-version package path by default
 	if pkg == "" {
-		pkg = "github.com/rbaliyan/go-version"
+		pkg = defaultPackage
 	}
 
 	var flags []string
 
 	if static {
 		// Static values - resolve everything now
-		commit := gitCommand("rev-parse", "HEAD")
-		branch := gitCommand("rev-parse", "--abbrev-ref", "HEAD")
-		repo := gitCommand("remote", "get-url", "origin")
+		git := gitInfo()
+		commit, branch, repo := git.Commit, git.Branch, git.Repo
+		dirty := git.Dirty == "true"
 
 		if ver == "" {
-			ver = gitCommand("describe", "--tags", "--always")
+			ver = git.Version
+		}
+		if dirtySuffix && dirty && ver != "" {
+			ver += "-dirty"
 		}
 
 		if ver != "" {
@@ -265,24 +431,38 @@ func cmdLdflags(args []string) {
 		if repo != "" {
 			flags = append(flags, fmt.Sprintf("-X '%s.GitRepo=%s'", pkg, repo))
 		}
+		if git.Dirty != "" {
+			flags = append(flags, fmt.Sprintf("-X '%s.GitDirty=%s'", pkg, git.Dirty))
+		}
 		if timestamp {
 			ts := dateCommand(timeformat)
 			flags = append(flags, fmt.Sprintf("-X '%s.BuildTimestamp=%s'", pkg, ts))
 		}
 	} else {
 		// Shell substitutions for use in scripts/Makefiles
-		if ver != "" {
+		dirtyTest := fmt.Sprintf("test -z \"$(git %s)\"", strings.Join(dirtyStatusArgs, " "))
+		switch {
+		case ver != "" && dirtySuffix:
+			flags = append(flags, fmt.Sprintf("-X '%s.VersionInfo=%s$(%s || echo -dirty)'", pkg, ver, dirtyTest))
+		case ver != "":
 			flags = append(flags, fmt.Sprintf("-X '%s.VersionInfo=%s'", pkg, ver))
-		} else {
+		case dirtySuffix:
+			flags = append(flags, fmt.Sprintf("-X '%s.VersionInfo=$(git describe --tags --always --dirty)'", pkg))
+		default:
 			flags = append(flags, fmt.Sprintf("-X '%s.VersionInfo=$(git describe --tags --always)'", pkg))
 		}
 		flags = append(flags, fmt.Sprintf("-X '%s.GitCommit=$(git rev-parse HEAD)'", pkg))
 		flags = append(flags, fmt.Sprintf("-X '%s.GitBranch=$(git rev-parse --abbrev-ref HEAD)'", pkg))
 		flags = append(flags, fmt.Sprintf("-X '%s.GitRepo=$(git remote get-url origin)'", pkg))
+		flags = append(flags, fmt.Sprintf("-X '%s.GitDirty=$(%s && echo false || echo true)'", pkg, dirtyTest))
 		if timestamp {
 			flags = append(flags, fmt.Sprintf("-X '%s.BuildTimestamp=$(date -u \"+%s\")'", pkg, timeformat))
 		}
 	}
+	// URL encoding also escapes quotes, so the value is safe in both modes
+	if len(extra) > 0 {
+		flags = append(flags, fmt.Sprintf("-X '%s.ExtraInfo=%s'", pkg, extra.encode()))
+	}
 
 	fmt.Println(strings.Join(flags, " "))
 }
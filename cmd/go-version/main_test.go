@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -10,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	version "github.com/rbaliyan/go-version"
 )
 
 // captureStdout runs fn and returns whatever it wrote to os.Stdout.
@@ -130,6 +133,59 @@ func TestCmdShow_HasValues(t *testing.T) {
 	}
 }
 
+func TestCmdShow_Formats(t *testing.T) {
+	requireGit(t)
+	commit := gitCommand("rev-parse", "HEAD")
+
+	output := captureStdout(t, func() { cmdShow([]string{"--format", "json"}) })
+	var doc struct {
+		Version string `json:"version"`
+		Git     struct {
+			Commit string `json:"commit"`
+		} `json:"git"`
+	}
+	if err := json.Unmarshal([]byte(output), &doc); err != nil {
+		t.Fatalf("show --format json is not JSON: %v\n%s", err, output)
+	}
+	if doc.Git.Commit != commit || doc.Version == "" {
+		t.Errorf("show --format json = %+v, want commit %s", doc, commit)
+	}
+
+	output = captureStdout(t, func() { cmdShow([]string{"-f", "env"}) })
+	if !strings.Contains(output, "GIT_COMMIT="+commit+"\n") {
+		t.Errorf("show -f env should contain GIT_COMMIT, got:\n%s", output)
+	}
+
+	output = captureStdout(t, func() { cmdShow([]string{"--template", "{{.Commit}}"}) })
+	if output != commit+"\n" {
+		t.Errorf("show --template = %q, want %q", output, commit+"\n")
+	}
+}
+
+func TestOutputOptions_Validate(t *testing.T) {
+	for _, o := range []outputOptions{{format: "text"}, {format: "json"}, {format: "yml"}, {format: "xml", template: "{{.Version}}"}} {
+		if err := o.validate(); err != nil {
+			t.Errorf("validate(%+v) error = %v", o, err)
+		}
+	}
+	for _, o := range []outputOptions{{format: "xml"}, {format: "text", template: "{{.Version"}} {
+		if err := o.validate(); err == nil {
+			t.Errorf("validate(%+v) should fail", o)
+		}
+	}
+	o := outputOptions{template: "{{.Unknown}}"}
+	if err := o.write(io.Discard, version.Metadata{}, nil); err == nil {
+		t.Error("template with an unknown field should fail")
+	}
+}
+
+func TestMain_ShowUnknownFormat(t *testing.T) {
+	out, err := runBinary(t, t.TempDir(), "show", "--format", "xml")
+	if err == nil || !strings.Contains(out, "unknown output format") {
+		t.Errorf("show --format xml should fail: %v\n%s", err, out)
+	}
+}
+
 // --- cmdFile tests ---
 
 func TestCmdFile_DefaultOutput(t *testing.T) {
@@ -256,11 +312,75 @@ func TestCmdFile_LongFlagNames(t *testing.T) {
 	}
 }
 
+func TestCmdFile_Formats(t *testing.T) {
+	requireGit(t)
+
+	for _, name := range []string{"version.json", "version.yaml", "version.toml", ".version"} {
+		t.Run(name, func(t *testing.T) {
+			outFile := filepath.Join(t.TempDir(), name)
+			captureStdout(t, func() {
+				cmdFile([]string{"-o", outFile, "-v", "1.2.3", "--name", "myapp", "--description", "My \"app\""})
+			})
+
+			info := version.New()
+			if err := info.LoadFromFile(outFile); err != nil {
+				t.Fatalf("LoadFromFile() error = %v", err)
+			}
+			if info.Get().Raw != "1.2.3" || info.App().Name != "myapp" || info.App().Description != `My "app"` {
+				t.Errorf("loaded %+v %+v", info.Get(), info.App())
+			}
+			if info.Git().Commit == "" || info.Build().Timestamp.IsZero() {
+				t.Errorf("git commit and timestamp should be written, got %+v", info.Build())
+			}
+		})
+	}
+}
+
+func TestCmdFile_Set(t *testing.T) {
+	requireGit(t)
+
+	outFile := filepath.Join(t.TempDir(), "version.json")
+	captureStdout(t, func() {
+		cmdFile([]string{"-o", outFile, "--set", "pipeline=1234", "--set", "channel=beta"})
+	})
+
+	info := version.New()
+	if err := info.LoadFromFile(outFile); err != nil {
+		t.Fatal(err)
+	}
+	if extra := info.Build().Extra; extra["pipeline"] != "1234" || extra["channel"] != "beta" {
+		t.Errorf("Extra = %v", extra)
+	}
+}
+
+func TestFileTarget(t *testing.T) {
+	tests := []struct {
+		format, output string
+		wantFormat     version.Format
+		wantOutput     string
+	}{
+		{"", "", version.FormatEnv, ".version"},
+		{"json", "", version.FormatJSON, ".version.json"},
+		{"yml", "", version.FormatYAML, ".version.yaml"},
+		{"", "deploy/version.toml", version.FormatTOML, "deploy/version.toml"},
+		{"json", "build/.version", version.FormatJSON, "build/.version"},
+	}
+	for _, tt := range tests {
+		f, out, err := fileTarget(tt.format, tt.output)
+		if err != nil || f != tt.wantFormat || out != tt.wantOutput {
+			t.Errorf("fileTarget(%q, %q) = %q, %q, %v; want %q, %q", tt.format, tt.output, f, out, err, tt.wantFormat, tt.wantOutput)
+		}
+	}
+	if _, _, err := fileTarget("xml", ""); err == nil {
+		t.Error("fileTarget(xml) should fail")
+	}
+}
+
 // --- cmdVersion tests ---
 
 func TestCmdVersion(t *testing.T) {
 	output := captureStdout(t, func() {
-		cmdVersion()
+		cmdVersion(nil)
 	})
 
 	if !strings.HasPrefix(output, "go-version") {
@@ -268,6 +388,20 @@ func TestCmdVersion(t *testing.T) {
 	}
 }
 
+func TestCmdVersion_JSON(t *testing.T) {
+	output := captureStdout(t, func() {
+		cmdVersion([]string{"--format", "json"})
+	})
+	var doc struct {
+		App struct {
+			Name string `json:"name"`
+		} `json:"app"`
+	}
+	if err := json.Unmarshal([]byte(output), &doc); err != nil || doc.App.Name != "go-version" {
+		t.Errorf("version --format json = %+v, %v\n%s", doc, err, output)
+	}
+}
+
 // --- cmdLdflags tests ---
 
 func TestCmdLdflags_ShellMode(t *testing.T) {
@@ -371,6 +505,97 @@ func TestCmdLdflags_CustomPackage(t *testing.T) {
 	}
 }
 
+func TestCmdLdflags_Set(t *testing.T) {
+	requireGit(t)
+
+	for _, static := range []bool{false, true} {
+		args := []string{"--set", "pipeline=12 34", "--set", "note=it's"}
+		if static {
+			args = append(args, "--static")
+		}
+		output := captureStdout(t, func() { cmdLdflags(args) })
+		if !strings.Contains(output, "-X 'github.com/rbaliyan/go-version.ExtraInfo=note=it%27s&pipeline=12+34'") {
+			t.Errorf("static=%v: ExtraInfo should be URL-encoded, got:\n%s", static, output)
+		}
+	}
+
+	output := captureStdout(t, func() { cmdLdflags(nil) })
+	if strings.Contains(output, "ExtraInfo") {
+		t.Errorf("ExtraInfo should be omitted without --set, got:\n%s", output)
+	}
+}
+
+func TestMain_LdflagsDirty(t *testing.T) {
+	dir := newTestRepo(t)
+	runIn(t, dir, "git", "tag", "v1.0.0")
+	// untracked files do not make the tree dirty
+	if err := os.WriteFile(filepath.Join(dir, "untracked"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := runBinary(t, dir, "ldflags", "--static", "--dirty", "--fail-if-dirty")
+	if err != nil {
+		t.Fatalf("ldflags failed on a clean tree: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "VersionInfo=v1.0.0'") || !strings.Contains(out, "GitDirty=false'") {
+		t.Errorf("clean tree output:\n%s", out)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runBinary(t, dir, "ldflags", "--static", "--dirty")
+	if err != nil {
+		t.Fatalf("ldflags failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "VersionInfo=v1.0.0-dirty'") || !strings.Contains(out, "GitDirty=true'") {
+		t.Errorf("dirty tree output:\n%s", out)
+	}
+
+	out, err = runBinary(t, dir, "ldflags", "--static")
+	if err != nil || strings.Contains(out, "-dirty") {
+		t.Errorf("version should not get -dirty without --dirty: %v\n%s", err, out)
+	}
+
+	out, err = runBinary(t, dir, "ldflags", "--fail-if-dirty")
+	if err == nil {
+		t.Fatalf("--fail-if-dirty should fail on a dirty tree:\n%s", out)
+	}
+	if !strings.Contains(out, "uncommitted changes") || !strings.Contains(out, "README") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestCmdLdflags_ShellModeDirty(t *testing.T) {
+	requireGit(t)
+
+	output := captureStdout(t, func() { cmdLdflags([]string{"--dirty"}) })
+	if !strings.Contains(output, "VersionInfo=$(git describe --tags --always --dirty)'") {
+		t.Errorf("should describe with --dirty, got:\n%s", output)
+	}
+	if !strings.Contains(output, "GitDirty=$(test -z") || !strings.Contains(output, "&& echo false || echo true)'") {
+		t.Errorf("should contain GitDirty substitution, got:\n%s", output)
+	}
+
+	output = captureStdout(t, func() { cmdLdflags([]string{"--dirty", "-v", "1.2.3"}) })
+	if !strings.Contains(output, "VersionInfo=1.2.3$(test -z") || !strings.Contains(output, "|| echo -dirty)'") {
+		t.Errorf("should append a -dirty substitution, got:\n%s", output)
+	}
+}
+
+func TestLabelsFlag(t *testing.T) {
+	l := labelsFlag{}
+	if err := l.Set("a=b=c"); err != nil || l["a"] != "b=c" {
+		t.Errorf("Set(a=b=c) = %v, %v", l, err)
+	}
+	if err := l.Set("novalue"); err == nil {
+		t.Error("Set(novalue) should fail")
+	}
+	if err := l.Set("=x"); err == nil {
+		t.Error("Set(=x) should fail")
+	}
+}
+
 func TestCmdLdflags_LongFlagNames(t *testing.T) {
 	requireGit(t)
 
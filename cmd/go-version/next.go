@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+const nextUsage = `Infer the next version from Conventional Commits since the latest tag
+
+Usage:
+  go-version next [options]
+
+Commits since the latest SemVer tag reachable from HEAD are parsed as
+Conventional Commits (type(scope)!: description). Breaking changes ("!" or a
+BREAKING CHANGE footer) bump the major version, or the minor version while the
+major version is 0. Other types bump according to the mapping below.
+
+Default mapping:
+  feat -> minor, fix -> patch, perf -> patch, anything else -> none
+
+Options:
+      --map          Override a type mapping as type=major|minor|patch|none (repeatable)
+      --json         Output JSON explaining which commits drove the decision
+
+Examples:
+  go-version next                                   # e.g. v1.3.0
+  go-version next --map refactor=patch --map docs=patch
+  go-version next --json | jq .bump
+  git tag -a "$(go-version next)" -m "Release"
+`
+
+// mappingFlag collects repeated type=level flags.
+type mappingFlag map[string]bumpLevel
+
+func (m mappingFlag) String() string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v.String())
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m mappingFlag) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected type=level, got %q", s)
+	}
+	level, err := parseBumpLevel(parts[1])
+	if err != nil {
+		return err
+	}
+	m[strings.ToLower(parts[0])] = level
+	return nil
+}
+
+// nextCommit is a commit with the bump it triggers, for JSON output.
+type nextCommit struct {
+	commit
+	Bump bumpLevel `json:"bump"`
+	// whether this commit required the final bump level
+	Decisive bool `json:"decisive"`
+}
+
+// nextResult is the JSON output of the next command.
+type nextResult struct {
+	Current string       `json:"current"`
+	Next    string       `json:"next"`
+	Bump    bumpLevel    `json:"bump"`
+	Commits []nextCommit `json:"commits"`
+}
+
+func cmdNext(args []string) {
+	fs := flag.NewFlagSet("next", flag.ExitOnError)
+	fs.Usage = func() { _, _ = os.Stdout.WriteString(nextUsage) }
+
+	types := mappingFlag{}
+	for k, v := range defaultTypeBumps {
+		types[k] = v
+	}
+	var asJSON bool
+	fs.Var(types, "map", "Type to bump mapping (type=level)")
+	fs.BoolVar(&asJSON, "json", false, "Output JSON")
+
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing flags: %v\n", err)
+		os.Exit(1)
+	}
+
+	if gitCommand("rev-parse", "--git-dir") == "" {
+		fmt.Fprintln(os.Stderr, "Error: not a git repository")
+		os.Exit(1)
+	}
+
+	current, revRange := sinceLatestTag()
+	result := inferNext(current, gitLog(revRange), types)
+
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if result.Bump == bumpNone {
+		fmt.Fprintf(os.Stderr, "No release-worthy commits since %s\n", current.Raw)
+	}
+	fmt.Println(result.Next)
+}
+
+// inferNext computes the next version from the commits since current.
+func inferNext(current version.Version, commits []commit, types map[string]bumpLevel) nextResult {
+	result := nextResult{Current: current.Raw, Commits: []nextCommit{}}
+	for _, c := range commits {
+		level := bumpFor(c, types, current)
+		if level > result.Bump {
+			result.Bump = level
+		}
+		result.Commits = append(result.Commits, nextCommit{commit: c, Bump: level})
+	}
+	for i := range result.Commits {
+		result.Commits[i].Decisive = result.Bump != bumpNone && result.Commits[i].Bump == result.Bump
+	}
+
+	next := current
+	switch result.Bump {
+	case bumpMajor:
+		next = current.IncMajor()
+	case bumpMinor:
+		next = current.IncMinor()
+	case bumpPatch:
+		next = current.IncPatch()
+	}
+	result.Next = next.Raw
+	return result
+}
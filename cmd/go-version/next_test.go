@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		subject  string
+		body     string
+		typ      string
+		scope    string
+		desc     string
+		breaking bool
+		note     string
+	}{
+		{"feat: add bump command", "", "feat", "", "add bump command", false, ""},
+		{"fix(cli): handle empty tags", "", "fix", "cli", "handle empty tags", false, ""},
+		{"feat!: drop Prefix", "", "feat", "", "drop Prefix", true, "drop Prefix"},
+		{"refactor(api)!: rename Get", "", "refactor", "api", "rename Get", true, "rename Get"},
+		{"fix: parse tags", "Details.\n\nBREAKING CHANGE: tags must be SemVer", "fix", "", "parse tags", true, "tags must be SemVer"},
+		{"fix: parse tags", "BREAKING-CHANGE: dashes too", "fix", "", "parse tags", true, "dashes too"},
+		{"Feat: capitalized type", "", "feat", "", "capitalized type", false, ""},
+		{"Merge branch 'main'", "", "", "", "", false, ""},
+		{"feat:missing space", "", "", "", "", false, ""},
+		{"update README", "BREAKING CHANGE: ignored without header", "", "", "", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			c := parseCommit("abc", tt.subject, tt.body)
+			if c.Type != tt.typ || c.Scope != tt.scope || c.Description != tt.desc {
+				t.Errorf("parseCommit() = type %q scope %q desc %q, want %q %q %q", c.Type, c.Scope, c.Description, tt.typ, tt.scope, tt.desc)
+			}
+			if c.Breaking != tt.breaking || c.BreakingNote != tt.note {
+				t.Errorf("breaking = %v %q, want %v %q", c.Breaking, c.BreakingNote, tt.breaking, tt.note)
+			}
+		})
+	}
+}
+
+func TestInferNext(t *testing.T) {
+	commits := func(subjects ...string) []commit {
+		var cs []commit
+		for i, s := range subjects {
+			cs = append(cs, parseCommit(strings.Repeat("a", i+1), s, ""))
+		}
+		return cs
+	}
+
+	tests := []struct {
+		name    string
+		current string
+		commits []commit
+		want    string
+		bump    bumpLevel
+	}{
+		{"fix only", "v1.2.3", commits("fix: a", "docs: b"), "v1.2.4", bumpPatch},
+		{"feat wins over fix", "v1.2.3", commits("fix: a", "feat: b"), "v1.3.0", bumpMinor},
+		{"breaking", "v1.2.3", commits("feat!: a", "fix: b"), "v2.0.0", bumpMajor},
+		{"breaking on 0.x bumps minor", "v0.4.2", commits("feat!: a"), "v0.5.0", bumpMinor},
+		{"nothing releasable", "v1.2.3", commits("docs: a", "chore: b", "not conventional"), "v1.2.3", bumpNone},
+		{"no commits", "v1.2.3", nil, "v1.2.3", bumpNone},
+		{"promotes prerelease", "v1.3.0-rc.1", commits("fix: a"), "v1.3.0", bumpPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := inferNext(version.MustParse(tt.current), tt.commits, defaultTypeBumps)
+			if got.Next != tt.want || got.Bump != tt.bump {
+				t.Errorf("inferNext() = %s (%s), want %s (%s)", got.Next, got.Bump, tt.want, tt.bump)
+			}
+		})
+	}
+}
+
+func TestInferNext_CustomMapping(t *testing.T) {
+	types := mappingFlag{}
+	for k, v := range defaultTypeBumps {
+		types[k] = v
+	}
+	if err := types.Set("docs=patch"); err != nil {
+		t.Fatal(err)
+	}
+	if err := types.Set("feat=patch"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := inferNext(version.MustParse("v1.0.0"), []commit{parseCommit("a", "feat: x", ""), parseCommit("b", "docs: y", "")}, types)
+	if got.Next != "v1.0.1" {
+		t.Errorf("inferNext() = %s, want v1.0.1", got.Next)
+	}
+
+	if err := types.Set("feat=huge"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+	if err := types.Set("nolevel"); err == nil {
+		t.Error("expected error for missing '='")
+	}
+}
+
+func TestInferNext_Decisive(t *testing.T) {
+	got := inferNext(version.MustParse("v1.0.0"), []commit{
+		parseCommit("a", "fix: x", ""),
+		parseCommit("b", "feat: y", ""),
+		parseCommit("c", "feat(ui): z", ""),
+	}, defaultTypeBumps)
+
+	var decisive []string
+	for _, c := range got.Commits {
+		if c.Decisive {
+			decisive = append(decisive, c.Hash)
+		}
+	}
+	if strings.Join(decisive, ",") != "b,c" {
+		t.Errorf("decisive commits = %v, want [b c]", decisive)
+	}
+}
+
+func TestMain_Next(t *testing.T) {
+	dir := newTestRepo(t)
+	runIn(t, dir, "git", "tag", "v1.4.0")
+	commitFile(t, dir, "a", "a", "fix: first")
+	commitFile(t, dir, "b", "b", "feat(cli): second")
+	commitFile(t, dir, "c", "c", "docs: third")
+
+	out, err := runBinary(t, dir, "next")
+	if err != nil {
+		t.Fatalf("next failed: %v\n%s", err, out)
+	}
+	if strings.TrimSpace(out) != "v1.5.0" {
+		t.Errorf("next = %q, want v1.5.0", out)
+	}
+
+	out, err = runBinary(t, dir, "next", "--map", "feat=patch")
+	if err != nil {
+		t.Fatalf("next failed: %v\n%s", err, out)
+	}
+	if strings.TrimSpace(out) != "v1.4.1" {
+		t.Errorf("next with mapping = %q, want v1.4.1", out)
+	}
+}
+
+func TestMain_NextJSON(t *testing.T) {
+	dir := newTestRepo(t)
+	runIn(t, dir, "git", "tag", "v1.0.0")
+	commitFile(t, dir, "a", "a", "fix: first")
+	runIn(t, dir, "git", "commit", "-q", "--allow-empty", "-m", "refactor: rework\n\nBREAKING CHANGE: new API")
+
+	out, err := runBinary(t, dir, "next", "--json")
+	if err != nil {
+		t.Fatalf("next failed: %v\n%s", err, out)
+	}
+
+	var result struct {
+		Current string `json:"current"`
+		Next    string `json:"next"`
+		Bump    string `json:"bump"`
+		Commits []struct {
+			Type     string `json:"type"`
+			Breaking bool   `json:"breaking"`
+			Bump     string `json:"bump"`
+			Decisive bool   `json:"decisive"`
+		} `json:"commits"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if result.Current != "v1.0.0" || result.Next != "v2.0.0" || result.Bump != "major" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Commits) != 2 {
+		t.Fatalf("commits = %+v, want 2", result.Commits)
+	}
+	// newest first
+	if c := result.Commits[0]; c.Type != "refactor" || !c.Breaking || c.Bump != "major" || !c.Decisive {
+		t.Errorf("commits[0] = %+v", c)
+	}
+	if c := result.Commits[1]; c.Type != "fix" || c.Bump != "patch" || c.Decisive {
+		t.Errorf("commits[1] = %+v", c)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+// outputOptionsUsage documents the flags added by outputOptions.register.
+const outputOptionsUsage = `  -f, --format       Output format: text, json, yaml, toml or env (default: text)
+      --template     Go text/template executed with the version file fields,
+                     e.g. '{{.Version}} {{.Commit}}'
+
+The json, yaml, toml and env formats use the version file schema (see the
+File type and 'go-version file'), json adds the git describe fields; text is
+for people and may change.
+`
+
+// outputOptions holds the --format and --template flags shared by the
+// commands that print version information.
+type outputOptions struct {
+	format   string
+	template string
+}
+
+func (o *outputOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.format, "f", "text", "Output format (text, json, yaml, toml, env)")
+	fs.StringVar(&o.format, "format", "text", "Output format (text, json, yaml, toml, env)")
+	fs.StringVar(&o.template, "template", "", "Go text/template for the output")
+}
+
+// validate checks the flags before any work is done.
+func (o *outputOptions) validate() error {
+	if o.template != "" {
+		_, err := template.New("output").Parse(o.template)
+		return err
+	}
+	if o.format == "text" {
+		return nil
+	}
+	_, err := version.ParseFormat(o.format)
+	if err != nil {
+		return fmt.Errorf("unknown output format %q (want text, json, yaml, toml or env)", o.format)
+	}
+	return nil
+}
+
+// write writes m to w using the template, the encoding of the format, or text
+// for the text format. JSON is the encoding of version.Metadata, the other
+// formats and the template use the version file fields.
+func (o *outputOptions) write(w io.Writer, m version.Metadata, text func(io.Writer)) error {
+	f := m.File()
+	if o.template != "" {
+		tmpl, err := template.New("output").Parse(o.template)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, f); err != nil {
+			return err
+		}
+		if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+			buf.WriteByte('\n')
+		}
+		_, err = w.Write(buf.Bytes())
+		return err
+	}
+	if o.format == "text" {
+		text(w)
+		return nil
+	}
+	format, err := version.ParseFormat(o.format)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if format == version.FormatJSON {
+		data, err = json.MarshalIndent(m, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = f.Encode(format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// extraText formats extra labels for text output, e.g. "a=1 b=2".
+func extraText(extra map[string]string) string {
+	labels := make([]string, 0, len(extra))
+	for k, v := range extra {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	return strings.Join(labels, " ")
+}
@@ -0,0 +1,134 @@
+package version
+
+// Compare compares ver to other using SemVer 2.0.0 precedence rules and returns
+// -1, 0 or +1. Build metadata is ignored.
+func (ver Version) Compare(other Version) int {
+	if c := compareInt(ver.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := compareInt(ver.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := compareInt(ver.Patch, other.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(ver.prerelease(), other.prerelease())
+}
+
+// LessThan reports whether ver has lower precedence than other.
+func (ver Version) LessThan(other Version) bool {
+	return ver.Compare(other) < 0
+}
+
+// GreaterThan reports whether ver has higher precedence than other.
+func (ver Version) GreaterThan(other Version) bool {
+	return ver.Compare(other) > 0
+}
+
+// Equal reports whether ver and other have the same precedence.
+// Versions differing only in build metadata are equal.
+func (ver Version) Equal(other Version) bool {
+	return ver.Compare(other) == 0
+}
+
+// IsPrerelease reports whether ver has prerelease identifiers.
+func (ver Version) IsPrerelease() bool {
+	return len(ver.prerelease()) > 0
+}
+
+// prerelease returns the prerelease identifiers, falling back to the
+// deprecated Prefix field for versions constructed by hand.
+func (ver Version) prerelease() []Identifier {
+	if len(ver.Prerelease) > 0 || ver.Prefix == "" {
+		return ver.Prerelease
+	}
+	if pre, err := parsePrerelease(ver.Prefix, ver.Prefix, Lenient); err == nil {
+		return pre
+	}
+	return []Identifier{Identifier(ver.Prefix)}
+}
+
+// Compare compares two prerelease identifiers and returns -1, 0 or +1.
+// Numeric identifiers are compared numerically and always have lower
+// precedence than alphanumeric identifiers, which are compared in ASCII order.
+func (id Identifier) Compare(other Identifier) int {
+	aNum, bNum := id.IsNumeric(), other.IsNumeric()
+	switch {
+	case aNum && bNum:
+		return compareNumeric(string(id), string(other))
+	case aNum:
+		return -1
+	case bNum:
+		return 1
+	}
+	switch {
+	case id < other:
+		return -1
+	case id > other:
+		return 1
+	}
+	return 0
+}
+
+// Collection is a list of versions implementing sort.Interface in ascending
+// precedence order.
+//
+//	sort.Sort(version.Collection(versions))
+type Collection []Version
+
+func (c Collection) Len() int           { return len(c) }
+func (c Collection) Less(i, j int) bool { return c[i].LessThan(c[j]) }
+func (c Collection) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// comparePrerelease compares prerelease identifier lists. A version without
+// prerelease has higher precedence than one with, and a longer list wins when
+// all preceding identifiers are equal.
+func comparePrerelease(a, b []Identifier) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := a[i].Compare(b[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(a), len(b))
+}
+
+// compareNumeric compares two digit strings of arbitrary length without
+// converting them, so oversized identifiers cannot overflow.
+func compareNumeric(a, b string) int {
+	a, b = trimZeros(a), trimZeros(b)
+	if c := compareInt(len(a), len(b)); c != 0 {
+		return c
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func trimZeros(s string) string {
+	for len(s) > 1 && s[0] == '0' {
+		s = s[1:]
+	}
+	return s
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
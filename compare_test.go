@@ -0,0 +1,100 @@
+package version
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"2.0.0", "2.1.0", -1},
+		{"2.1.0", "2.1.1", -1},
+		{"2.1.1", "2.1.0", 1},
+		{"1.10.0", "1.9.0", 1},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-1", "1.0.0-a", -1},
+		{"1.0.0-A", "1.0.0-a", -1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"1.0.0-rc.1+a", "1.0.0-rc.1+b", 0},
+		{"1.0.0-99999999999999999999", "1.0.0-100000000000000000000", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_vs_"+tt.b, func(t *testing.T) {
+			a, b := MustParse(tt.a), MustParse(tt.b)
+			if got := a.Compare(b); got != tt.want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := b.Compare(a); got != -tt.want {
+				t.Errorf("Compare(%s, %s) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+			if got := a.LessThan(b); got != (tt.want < 0) {
+				t.Errorf("LessThan = %v, want %v", got, tt.want < 0)
+			}
+			if got := a.GreaterThan(b); got != (tt.want > 0) {
+				t.Errorf("GreaterThan = %v, want %v", got, tt.want > 0)
+			}
+			if got := a.Equal(b); got != (tt.want == 0) {
+				t.Errorf("Equal = %v, want %v", got, tt.want == 0)
+			}
+		})
+	}
+}
+
+func TestCompare_LegacyPrefix(t *testing.T) {
+	legacy := Version{Major: 1, Prefix: "rc.1"}
+	if !legacy.LessThan(MustParse("1.0.0")) {
+		t.Error("version with Prefix should sort before the release")
+	}
+	if !legacy.Equal(MustParse("1.0.0-rc.1")) {
+		t.Error("Prefix should compare like the equivalent Prerelease")
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	if MustParse("1.0.0").IsPrerelease() {
+		t.Error("1.0.0 should not be a prerelease")
+	}
+	if !MustParse("1.0.0-rc.1").IsPrerelease() {
+		t.Error("1.0.0-rc.1 should be a prerelease")
+	}
+	if MustParse("1.0.0+build").IsPrerelease() {
+		t.Error("build metadata should not make a prerelease")
+	}
+}
+
+func TestCollection_Sort(t *testing.T) {
+	input := []string{
+		"1.0.0", "1.0.0-rc.1", "0.9.0", "1.0.0-beta.11", "1.0.0-alpha",
+		"1.0.0-beta.2", "2.0.0", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-alpha.1",
+	}
+	want := []string{
+		"0.9.0", "1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "2.0.0",
+	}
+
+	versions := make(Collection, len(input))
+	for i, s := range input {
+		versions[i] = MustParse(s)
+	}
+	sort.Sort(versions)
+
+	for i, v := range versions {
+		if v.String() != want[i] {
+			t.Errorf("sorted[%d] = %s, want %s", i, v, want[i])
+		}
+	}
+}
@@ -7,19 +7,47 @@ _go_version() {
     cur="${COMP_WORDS[COMP_CWORD]}"
     prev="${COMP_WORDS[COMP_CWORD-1]}"
 
-    commands="file ldflags show version help"
+    commands="bump changelog file inspect ldflags next show version help"
 
     case "${prev}" in
         go-version)
             COMPREPLY=( $(compgen -W "${commands}" -- "${cur}") )
             return 0
             ;;
+        bump)
+            COMPREPLY=( $(compgen -W "major minor patch prerelease --pre --tag -m --message --push --remote --dry-run -h" -- "${cur}") )
+            return 0
+            ;;
+        major|minor|patch|prerelease)
+            COMPREPLY=( $(compgen -W "--pre --tag -m --message --push --remote --dry-run -h" -- "${cur}") )
+            return 0
+            ;;
+        changelog)
+            COMPREPLY=( $(compgen -W "--from --to --version --date --repo --all --prepend --format --package -o --output -h" -- "${cur}") )
+            return 0
+            ;;
+        --prepend)
+            COMPREPLY=( $(compgen -f -- "${cur}") )
+            return 0
+            ;;
         file)
-            COMPREPLY=( $(compgen -W "-o --output -v --version -t --timestamp --timeformat -h" -- "${cur}") )
+            COMPREPLY=( $(compgen -W "-o --output -f --format -v --version -t --timestamp --timeformat --name --description --set -h" -- "${cur}") )
             return 0
             ;;
         ldflags)
-            COMPREPLY=( $(compgen -W "-p --package -v --version -t --timestamp --timeformat --static --shell -h" -- "${cur}") )
+            COMPREPLY=( $(compgen -W "-p --package -v --version -t --timestamp --timeformat --static --shell --set --dirty --fail-if-dirty -h" -- "${cur}") )
+            return 0
+            ;;
+        next)
+            COMPREPLY=( $(compgen -W "--map --json -h" -- "${cur}") )
+            return 0
+            ;;
+        show|version)
+            COMPREPLY=( $(compgen -W "-f --format --template -h" -- "${cur}") )
+            return 0
+            ;;
+        inspect)
+            COMPREPLY=( $(compgen -W "-p --package -f --format --template -h" -- "${cur}") $(compgen -f -- "${cur}") )
             return 0
             ;;
         -o|--output)
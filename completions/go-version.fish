@@ -4,17 +4,35 @@
 complete -c go-version -f
 
 # Commands
+complete -c go-version -n "__fish_use_subcommand" -a "bump" -d "Compute the next version and optionally tag it"
+complete -c go-version -n "__fish_use_subcommand" -a "changelog" -d "Generate a changelog section from git history"
 complete -c go-version -n "__fish_use_subcommand" -a "file" -d "Generate a .version file"
+complete -c go-version -n "__fish_use_subcommand" -a "inspect" -d "Show the version info compiled into a binary"
 complete -c go-version -n "__fish_use_subcommand" -a "ldflags" -d "Generate ldflags for go build"
+complete -c go-version -n "__fish_use_subcommand" -a "next" -d "Infer the next version from Conventional Commits"
 complete -c go-version -n "__fish_use_subcommand" -a "show" -d "Display current git information"
 complete -c go-version -n "__fish_use_subcommand" -a "version" -d "Show go-version version"
 complete -c go-version -n "__fish_use_subcommand" -a "help" -d "Show help"
 
+# bump subcommand options
+complete -c go-version -n "__fish_seen_subcommand_from bump" -a "major minor patch prerelease"
+complete -c go-version -n "__fish_seen_subcommand_from bump" -l pre -d "Prerelease identifier" -r
+complete -c go-version -n "__fish_seen_subcommand_from bump" -l tag -d "Create an annotated tag"
+complete -c go-version -n "__fish_seen_subcommand_from bump" -s m -l message -d "Tag message" -r
+complete -c go-version -n "__fish_seen_subcommand_from bump" -l push -d "Push the new tag"
+complete -c go-version -n "__fish_seen_subcommand_from bump" -l remote -d "Remote to push to" -r
+complete -c go-version -n "__fish_seen_subcommand_from bump" -l dry-run -d "Print what would be done"
+complete -c go-version -n "__fish_seen_subcommand_from bump" -s h -d "Show help"
+
 # file subcommand options
 complete -c go-version -n "__fish_seen_subcommand_from file" -s o -l output -d "Output file path" -r
+complete -c go-version -n "__fish_seen_subcommand_from file" -s f -l format -d "File format" -r -a "env json yaml toml"
 complete -c go-version -n "__fish_seen_subcommand_from file" -s v -l version -d "Version string" -r
 complete -c go-version -n "__fish_seen_subcommand_from file" -s t -l timestamp -d "Build timestamp" -r
 complete -c go-version -n "__fish_seen_subcommand_from file" -l timeformat -d "Timestamp format for date command" -r
+complete -c go-version -n "__fish_seen_subcommand_from file" -l name -d "Application name" -r
+complete -c go-version -n "__fish_seen_subcommand_from file" -l description -d "Application description" -r
+complete -c go-version -n "__fish_seen_subcommand_from file" -l set -d "Extra label key=value" -r
 complete -c go-version -n "__fish_seen_subcommand_from file" -s h -d "Show help"
 
 # ldflags subcommand options
@@ -24,4 +42,32 @@ complete -c go-version -n "__fish_seen_subcommand_from ldflags" -s t -l timestam
 complete -c go-version -n "__fish_seen_subcommand_from ldflags" -l timeformat -d "Timestamp format for date command" -r
 complete -c go-version -n "__fish_seen_subcommand_from ldflags" -l static -d "Output static values"
 complete -c go-version -n "__fish_seen_subcommand_from ldflags" -l shell -d "Output shell substitutions"
+complete -c go-version -n "__fish_seen_subcommand_from ldflags" -l set -d "Extra label key=value" -r
+complete -c go-version -n "__fish_seen_subcommand_from ldflags" -l dirty -d "Append -dirty to a dirty version"
+complete -c go-version -n "__fish_seen_subcommand_from ldflags" -l fail-if-dirty -d "Fail on uncommitted changes"
 complete -c go-version -n "__fish_seen_subcommand_from ldflags" -s h -d "Show help"
+
+# next subcommand options
+complete -c go-version -n "__fish_seen_subcommand_from next" -l map -d "Type to bump mapping (type=level)" -r
+complete -c go-version -n "__fish_seen_subcommand_from next" -l json -d "Output JSON"
+complete -c go-version -n "__fish_seen_subcommand_from next" -s h -d "Show help"
+
+# changelog subcommand options
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l from -d "Start ref" -r
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l to -d "End ref" -r
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l version -d "Section version" -r
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l date -d "Section date" -r
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l repo -d "Repository URL" -r
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l all -d "Include all commits"
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l prepend -d "Insert into Markdown file" -r -F
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l format -d "Output format" -r -a "markdown go"
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -l package -d "Package name for go format" -r
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -s o -l output -d "Output file" -r -F
+complete -c go-version -n "__fish_seen_subcommand_from changelog" -s h -d "Show help"
+
+# show, version and inspect output options
+complete -c go-version -n "__fish_seen_subcommand_from show version inspect" -s f -l format -d "Output format" -r -a "text json yaml toml env"
+complete -c go-version -n "__fish_seen_subcommand_from show version inspect" -l template -d "Go text/template" -r
+complete -c go-version -n "__fish_seen_subcommand_from show version inspect" -s h -d "Show help"
+complete -c go-version -n "__fish_seen_subcommand_from inspect" -s p -l package -d "Package path" -r
+complete -c go-version -n "__fish_seen_subcommand_from inspect" -F
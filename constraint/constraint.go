@@ -0,0 +1,493 @@
+// Package constraint implements npm/Cargo style version range expressions
+// evaluated against version.Version.
+//
+// A constraint is one or more ranges joined by "||". A range is a list of
+// comparators separated by whitespace or commas, all of which must match:
+//
+//	>=1.2.0 <2.0.0        comparison operators: = != > >= < <=
+//	~1.4  ~1.4.2  ~>1.4   tilde: patch-level changes (>=1.4.0 <1.5.0)
+//	^0.3  ^1.2.3          caret: changes that do not modify the left-most non-zero part
+//	1.2.3 - 2.3           hyphen range (>=1.2.3 <2.4.0)
+//	1.x  1.2.*  *         wildcards
+//	^1.2 || ^2.0          either range
+//
+// Prerelease versions only satisfy a range if one of its comparators names a
+// prerelease with the same major.minor.patch, so ">=1.2.0-rc.1" matches
+// 1.2.0-rc.2 but not 1.3.0-beta.
+//
+// # Usage
+//
+//	c, err := constraint.Parse(">=1.4.0 <2")
+//	if err != nil { ... }
+//	if err := c.Validate(version.Get()); err != nil {
+//	    log.Fatal(err) // version 1.3.0 does not satisfy ">=1.4.0 <2": ...
+//	}
+package constraint
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+// ErrInvalidConstraint is returned (wrapped in a *ParseError) when a
+// constraint expression cannot be parsed.
+var ErrInvalidConstraint = errors.New("invalid constraint")
+
+// ParseError describes why a constraint expression could not be parsed.
+type ParseError struct {
+	// constraint expression as passed to Parse
+	Input string
+	// human readable reason
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid constraint %q: %s", e.Input, e.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidConstraint).
+func (e *ParseError) Unwrap() error {
+	return ErrInvalidConstraint
+}
+
+// Violation names the clause of a range that a version failed.
+type Violation struct {
+	// clause as written in the expression, e.g. "~1.2"
+	Clause string
+	// why the clause did not match
+	Reason string
+}
+
+// UnsatisfiedError is returned by Validate and lists, for every OR-ed range,
+// the first clause the version failed.
+type UnsatisfiedError struct {
+	// version that was checked
+	Version version.Version
+	// constraint expression
+	Constraint string
+	// one violation per range
+	Violations []Violation
+}
+
+func (e *UnsatisfiedError) Error() string {
+	reasons := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		reasons[i] = v.Reason
+	}
+	return fmt.Sprintf("version %s does not satisfy %q: %s", e.Version, e.Constraint, strings.Join(reasons, "; "))
+}
+
+// Constraint is a parsed range expression. The zero value matches nothing;
+// use Parse to build one.
+type Constraint struct {
+	raw    string
+	ranges [][]comparator
+}
+
+// Parse parses a constraint expression.
+func Parse(s string) (*Constraint, error) {
+	c := &Constraint{raw: strings.TrimSpace(s)}
+	for _, part := range strings.Split(s, "||") {
+		r, err := parseRange(s, part)
+		if err != nil {
+			return nil, err
+		}
+		c.ranges = append(c.ranges, r)
+	}
+	return c, nil
+}
+
+// MustParse is like Parse but panics if s is not a valid expression.
+func MustParse(s string) *Constraint {
+	c, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// String returns the expression the constraint was parsed from.
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Check reports whether v satisfies the constraint.
+func (c *Constraint) Check(v version.Version) bool {
+	for _, r := range c.ranges {
+		if failing(r, v) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns nil if v satisfies the constraint, otherwise an
+// *UnsatisfiedError explaining which clause of each range failed.
+func (c *Constraint) Validate(v version.Version) error {
+	uerr := &UnsatisfiedError{Version: v, Constraint: c.raw}
+	for _, r := range c.ranges {
+		viol := failing(r, v)
+		if viol == nil {
+			return nil
+		}
+		uerr.Violations = append(uerr.Violations, *viol)
+	}
+	return uerr
+}
+
+type operator string
+
+const (
+	opEQ operator = "="
+	opNE operator = "!="
+	opGT operator = ">"
+	opGE operator = ">="
+	opLT operator = "<"
+	opLE operator = "<="
+)
+
+// comparator is a primitive "op version" test. Every clause of an expression
+// desugars into one or two comparators that keep the original clause text.
+type comparator struct {
+	op     operator
+	ver    version.Version
+	clause string
+}
+
+func (c comparator) String() string {
+	return string(c.op) + c.ver.String()
+}
+
+func (c comparator) match(v version.Version) bool {
+	cmp := v.Compare(c.ver)
+	switch c.op {
+	case opEQ:
+		return cmp == 0
+	case opNE:
+		return cmp != 0
+	case opGT:
+		return cmp > 0
+	case opGE:
+		return cmp >= 0
+	case opLT:
+		return cmp < 0
+	case opLE:
+		return cmp <= 0
+	}
+	return false
+}
+
+// failing returns the first violation of range r by v, or nil if v matches.
+func failing(r []comparator, v version.Version) *Violation {
+	for _, c := range r {
+		if !c.match(v) {
+			return &Violation{
+				Clause: c.clause,
+				Reason: fmt.Sprintf("%s is not %s (from %q)", v, c, c.clause),
+			}
+		}
+	}
+	if !v.IsPrerelease() {
+		return nil
+	}
+	// A prerelease only matches when the range opts into prereleases of the
+	// same major.minor.patch.
+	for _, c := range r {
+		if c.ver.IsPrerelease() && sameTuple(c.ver, v) {
+			return nil
+		}
+	}
+	clauses := make([]string, len(r))
+	for i, c := range r {
+		clauses[i] = c.clause
+	}
+	clause := strings.Join(dedupe(clauses), " ")
+	return &Violation{
+		Clause: clause,
+		Reason: fmt.Sprintf("%s is a prerelease and %q does not allow prereleases of %d.%d.%d", v, clause, v.Major, v.Minor, v.Patch),
+	}
+}
+
+func sameTuple(a, b version.Version) bool {
+	return a.Major == b.Major && a.Minor == b.Minor && a.Patch == b.Patch
+}
+
+func dedupe(s []string) []string {
+	out := s[:0]
+	for i, v := range s {
+		if i == 0 || s[i-1] != v {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// parseRange parses one AND-ed range (the text between "||").
+func parseRange(input, s string) ([]comparator, error) {
+	tokens := tokenize(s)
+	if len(tokens) == 0 {
+		return nil, invalid(input, "empty range")
+	}
+
+	// Hyphen range: A - B
+	if len(tokens) == 3 && tokens[1] == "-" {
+		return hyphenRange(input, tokens[0], tokens[2])
+	}
+
+	var r []comparator
+	for _, tok := range tokens {
+		if tok == "-" {
+			return nil, invalid(input, "hyphen ranges must have the form \"A - B\"")
+		}
+		cs, err := parseClause(input, tok)
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, cs...)
+	}
+	return r, nil
+}
+
+// tokenize splits a range on whitespace and commas, gluing a lone operator to
+// the following version so "> = 1.2" style spacing is tolerated.
+func tokenize(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		for isOperator(f) && i+1 < len(fields) {
+			i++
+			f += fields[i]
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+func isOperator(s string) bool {
+	switch s {
+	case "=", "==", "!=", ">", ">=", "<", "<=", "~", "~>", "^":
+		return true
+	}
+	return false
+}
+
+// parseClause desugars a single clause such as "~1.2" or ">=1.0.0" into
+// primitive comparators.
+func parseClause(input, clause string) ([]comparator, error) {
+	op, rest := splitOperator(clause)
+	p, err := parsePartial(input, rest)
+	if err != nil {
+		return nil, err
+	}
+	mk := func(o operator, v version.Version) comparator {
+		return comparator{op: o, ver: v, clause: clause}
+	}
+
+	switch op {
+	case "~", "~>":
+		// ~1.2.3 := >=1.2.3 <1.3.0, ~1.2 := >=1.2.0 <1.3.0, ~1 := >=1.0.0 <2.0.0
+		if p.n == 0 {
+			return []comparator{mk(opGE, zero())}, nil
+		}
+		upper := p.bump(1)
+		if p.n == 1 {
+			upper = p.bump(0)
+		}
+		return []comparator{mk(opGE, p.lower()), mk(opLT, upper)}, nil
+
+	case "^":
+		// Allow changes that do not modify the left-most non-zero component
+		// among those specified.
+		if p.n == 0 {
+			return []comparator{mk(opGE, zero())}, nil
+		}
+		var upper version.Version
+		switch {
+		case p.major != 0 || p.n == 1:
+			upper = p.bump(0)
+		case p.minor != 0 || p.n == 2:
+			upper = p.bump(1)
+		default:
+			upper = p.bump(2)
+		}
+		return []comparator{mk(opGE, p.lower()), mk(opLT, upper)}, nil
+
+	case "", "=", "==":
+		if p.n == 3 {
+			return []comparator{mk(opEQ, p.full)}, nil
+		}
+		if p.n == 0 {
+			return []comparator{mk(opGE, zero())}, nil
+		}
+		return []comparator{mk(opGE, p.lower()), mk(opLT, p.bump(p.n-1))}, nil
+
+	case "!=":
+		if p.n != 3 {
+			return nil, invalid(input, fmt.Sprintf("%q requires a full version", clause))
+		}
+		return []comparator{mk(opNE, p.full)}, nil
+
+	case ">":
+		switch p.n {
+		case 3:
+			return []comparator{mk(opGT, p.full)}, nil
+		case 0:
+			// nothing is greater than every version
+			return []comparator{mk(opLT, zero())}, nil
+		}
+		return []comparator{mk(opGE, p.bump(p.n-1))}, nil
+
+	case ">=":
+		return []comparator{mk(opGE, p.lower())}, nil
+
+	case "<":
+		if p.n == 3 {
+			return []comparator{mk(opLT, p.full)}, nil
+		}
+		return []comparator{mk(opLT, p.lower())}, nil
+
+	case "<=":
+		switch p.n {
+		case 3:
+			return []comparator{mk(opLE, p.full)}, nil
+		case 0:
+			return []comparator{mk(opGE, zero())}, nil
+		}
+		return []comparator{mk(opLT, p.bump(p.n-1))}, nil
+	}
+	return nil, invalid(input, fmt.Sprintf("unknown operator in %q", clause))
+}
+
+func hyphenRange(input, from, to string) ([]comparator, error) {
+	clause := from + " - " + to
+	lo, err := parsePartial(input, from)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := parsePartial(input, to)
+	if err != nil {
+		return nil, err
+	}
+	r := []comparator{{op: opGE, ver: lo.lower(), clause: clause}}
+	switch hi.n {
+	case 3:
+		r = append(r, comparator{op: opLE, ver: hi.full, clause: clause})
+	case 0:
+		// open upper bound
+	default:
+		r = append(r, comparator{op: opLT, ver: hi.bump(hi.n - 1), clause: clause})
+	}
+	return r, nil
+}
+
+func splitOperator(s string) (string, string) {
+	for _, op := range []string{"~>", ">=", "<=", "!=", "==", "~", "^", ">", "<", "="} {
+		if strings.HasPrefix(s, op) {
+			return op, s[len(op):]
+		}
+	}
+	return "", s
+}
+
+// partial is a possibly incomplete version such as "1", "1.2", "1.x" or "*".
+type partial struct {
+	major, minor, patch int
+	// number of leading numeric components that were specified (0-3)
+	n int
+	// complete version, only valid when n == 3
+	full version.Version
+}
+
+func parsePartial(input, s string) (partial, error) {
+	s = strings.TrimPrefix(s, "v")
+	if s == "" {
+		return partial{}, invalid(input, "missing version")
+	}
+
+	core := s
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return partial{}, invalid(input, fmt.Sprintf("too many components in %q", s))
+	}
+
+	var p partial
+	nums := [3]*int{&p.major, &p.minor, &p.patch}
+	wildcard := false
+	for i, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			wildcard = true
+			continue
+		}
+		if wildcard {
+			return partial{}, invalid(input, fmt.Sprintf("numeric component after wildcard in %q", s))
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || !isDigits(part) {
+			return partial{}, invalid(input, fmt.Sprintf("invalid component %q in %q", part, s))
+		}
+		*nums[i] = n
+		p.n++
+	}
+
+	if p.n == 3 {
+		v, err := version.Parse(s)
+		if err != nil {
+			return partial{}, invalid(input, err.Error())
+		}
+		p.full = v
+	} else if core != s {
+		return partial{}, invalid(input, fmt.Sprintf("prerelease or build metadata requires a full version in %q", s))
+	}
+	return p, nil
+}
+
+// lower returns the smallest version matching the partial.
+func (p partial) lower() version.Version {
+	if p.n == 3 {
+		return p.full
+	}
+	return version.Version{Major: p.major, Minor: p.minor, Patch: p.patch}
+}
+
+// bump returns the version with component i incremented and all lower
+// components zeroed, i.e. the exclusive upper bound of the partial at that
+// component.
+func (p partial) bump(i int) version.Version {
+	switch i {
+	case 0:
+		return version.Version{Major: p.major + 1}
+	case 1:
+		return version.Version{Major: p.major, Minor: p.minor + 1}
+	}
+	return version.Version{Major: p.major, Minor: p.minor, Patch: p.patch + 1}
+}
+
+func zero() version.Version {
+	return version.Version{}
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func invalid(input, reason string) error {
+	return &ParseError{Input: input, Reason: reason}
+}
@@ -0,0 +1,201 @@
+package constraint
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	version "github.com/rbaliyan/go-version"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		// comparison operators
+		{">=1.2.0 <2.0.0", "1.2.0", true},
+		{">=1.2.0 <2.0.0", "1.9.9", true},
+		{">=1.2.0 <2.0.0", "2.0.0", false},
+		{">=1.2.0 <2.0.0", "1.1.9", false},
+		{">=1.2.0, <2.0.0", "1.5.0", true},
+		{">= 1.2.0", "1.2.0", true},
+		{">1.2.3", "1.2.3", false},
+		{">1.2.3", "1.2.4", true},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<1.2", "1.1.9", true},
+		{"<1.2", "1.2.0", false},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{"=1.2.3", "1.2.3", true},
+		{"1.2.3", "1.2.3+build", true},
+		{"1.2.3", "1.2.4", false},
+		{"!=1.2.3", "1.2.4", true},
+		{"!=1.2.3", "1.2.3", false},
+		{"v1.2.3", "1.2.3", true},
+
+		// tilde
+		{"~1.4", "1.4.0", true},
+		{"~1.4", "1.4.9", true},
+		{"~1.4", "1.5.0", false},
+		{"~1.4.2", "1.4.1", false},
+		{"~1.4.2", "1.4.7", true},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+		{"~>1.4", "1.4.3", true},
+
+		// caret
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^1.2.3", "1.2.2", false},
+		{"^0.3", "0.3.9", true},
+		{"^0.3", "0.4.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.0", "0.0.9", true},
+		{"^0.0", "0.1.0", false},
+		{"^0", "0.9.0", true},
+		{"^0", "1.0.0", false},
+		{"^1", "1.99.0", true},
+
+		// hyphen ranges
+		{"1.2.3 - 2.3.4", "1.2.3", true},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2.3 - 2.3.4", "2.3.5", false},
+		{"1.2 - 2.3", "2.3.9", true},
+		{"1.2 - 2.3", "2.4.0", false},
+		{"1.2 - 2.3", "1.1.9", false},
+
+		// wildcards
+		{"*", "5.0.0", true},
+		{"x", "0.0.1", true},
+		{"1.x", "1.9.9", true},
+		{"1.x", "2.0.0", false},
+		{"1.2.*", "1.2.7", true},
+		{"1.2.*", "1.3.0", false},
+		{"1.2.x", "1.1.0", false},
+		{"1", "1.5.0", true},
+
+		// OR-ed ranges
+		{"^1.2 || ^2.0", "2.5.0", true},
+		{"^1.2 || ^2.0", "3.0.0", false},
+		{"<1.0.0 || >=3.0.0", "0.5.0", true},
+		{"<1.0.0 || >=3.0.0", "2.0.0", false},
+
+		// prereleases only match ranges naming the same major.minor.patch
+		{">=1.2.0-rc.1", "1.2.0-rc.2", true},
+		{">=1.2.0-rc.1", "1.2.0-beta", false},
+		{">=1.2.0-rc.1", "1.3.0-beta", false},
+		{">=1.2.0-rc.1", "1.3.0", true},
+		{">=1.2.0 <2.0.0", "1.5.0-rc.1", false},
+		{"<2.0.0", "2.0.0-rc.1", false},
+		{"*", "1.0.0-alpha", false},
+		{"^1.2.3-beta.2", "1.2.3-beta.4", true},
+		{"^1.2.3-beta.2", "1.2.4-beta.2", false},
+		{"1.2.3-rc.1 - 2", "1.2.3-rc.2", true},
+		{"<1.0.0 || >=2.0.0-rc.1", "2.0.0-rc.3", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+"/"+tt.version, func(t *testing.T) {
+			c, err := Parse(tt.constraint)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.constraint, err)
+			}
+			v := version.MustParse(tt.version)
+			if got := c.Check(v); got != tt.want {
+				t.Errorf("Check(%s) = %v, want %v", tt.version, got, tt.want)
+			}
+			if err := c.Validate(v); (err == nil) != tt.want {
+				t.Errorf("Validate(%s) = %v, want ok=%v", tt.version, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"   ",
+		"||",
+		"^1.2 ||",
+		">=a.b.c",
+		"1.2.3.4",
+		"1.x.3",
+		"1.2-rc.1",
+		"!=1.2",
+		"1.2.3 -",
+		"1.2.3 - 2 - 3",
+		">=01.2.3",
+		"1.2.3-",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			_, err := Parse(input)
+			if err == nil {
+				t.Fatalf("Parse(%q) should fail", input)
+			}
+			if !errors.Is(err, ErrInvalidConstraint) {
+				t.Errorf("errors.Is(err, ErrInvalidConstraint) = false for %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate_Diagnostic(t *testing.T) {
+	c := MustParse(">=1.4.0 <2 || ~3.1")
+	err := c.Validate(version.MustParse("1.3.0"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	var uerr *UnsatisfiedError
+	if !errors.As(err, &uerr) {
+		t.Fatalf("error should be *UnsatisfiedError, got %T", err)
+	}
+	if len(uerr.Violations) != 2 {
+		t.Fatalf("Violations = %v, want one per range", uerr.Violations)
+	}
+	if uerr.Violations[0].Clause != ">=1.4.0" {
+		t.Errorf("Violations[0].Clause = %q, want %q", uerr.Violations[0].Clause, ">=1.4.0")
+	}
+	if uerr.Violations[1].Clause != "~3.1" {
+		t.Errorf("Violations[1].Clause = %q, want %q", uerr.Violations[1].Clause, "~3.1")
+	}
+
+	msg := err.Error()
+	for _, want := range []string{"1.3.0", `">=1.4.0 <2 || ~3.1"`, "is not >=1.4.0", `(from "~3.1")`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q should contain %q", msg, want)
+		}
+	}
+}
+
+func TestValidate_PrereleaseDiagnostic(t *testing.T) {
+	err := MustParse(">=1.2.0 <2.0.0").Validate(version.MustParse("1.5.0-rc.1"))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "prerelease") {
+		t.Errorf("error should mention prerelease, got %q", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	c := MustParse("  ^1.2 || ~2.0  ")
+	if c.String() != "^1.2 || ~2.0" {
+		t.Errorf("String() = %q", c.String())
+	}
+}
+
+func TestMustParse_Panics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParse should panic on invalid input")
+		}
+	}()
+	MustParse(">>1")
+}
@@ -0,0 +1,128 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Describe is the output of git describe --tags --always, e.g.
+// v1.2.3-4-gabc1234-dirty.
+type Describe struct {
+	// nearest tag reachable from the commit, "" if there is none
+	Tag string `json:"tag,omitempty"`
+	// number of commits since Tag
+	Distance int `json:"distance"`
+	// abbreviated commit name, "" if the commit is tagged
+	ShortHash string `json:"short_hash,omitempty"`
+	// whether the output had a -dirty suffix
+	Dirty bool `json:"dirty,omitempty"`
+}
+
+// describeSuffix matches the "-<distance>-g<hash>" that git describe appends
+// to the tag of an untagged commit.
+var describeSuffix = regexp.MustCompile(`^(.+)-(\d+)-g([0-9a-f]{4,64})$`)
+
+// ParseDescribe parses git describe output: "<tag>-<distance>-g<hash>" or a
+// bare abbreviated commit name when no tag is reachable, each optionally
+// followed by -dirty. A bare tag, with or without -dirty, is not recognized
+// because it cannot be told apart from a plain version string such as
+// 1.2.3-dirty, which Parse reads as a prerelease of 1.2.3.
+func ParseDescribe(s string) (Describe, bool) {
+	var d Describe
+	if rest := strings.TrimSuffix(s, "-dirty"); rest != s {
+		d.Dirty = true
+		s = rest
+	}
+	if m := describeSuffix.FindStringSubmatch(s); m != nil {
+		distance, err := strconv.Atoi(m[2])
+		if err != nil {
+			return Describe{}, false
+		}
+		d.Tag, d.Distance, d.ShortHash = m[1], distance, m[3]
+		return d, true
+	}
+	if isShortHash(s) {
+		d.ShortHash = s
+		return d, true
+	}
+	return Describe{}, false
+}
+
+// isShortHash reports whether s looks like an abbreviated commit name.
+func isShortHash(s string) bool {
+	if len(s) < 4 || len(s) > 64 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// IsZero reports whether d holds no describe output.
+func (d Describe) IsZero() bool {
+	return d == Describe{}
+}
+
+// String returns d in git describe format.
+func (d Describe) String() string {
+	var s string
+	switch {
+	case d.Tag == "":
+		s = d.ShortHash
+	case d.Distance == 0 && d.ShortHash == "":
+		s = d.Tag
+	default:
+		s = fmt.Sprintf("%s-%d-g%s", d.Tag, d.Distance, d.ShortHash)
+	}
+	if d.Dirty {
+		s += "-dirty"
+	}
+	return s
+}
+
+// SemVer converts d into a semantic version that sorts after the tag and
+// before the next release:
+//
+//	v1.2.3                  1.2.3
+//	v1.2.3-4-gabc1234       1.2.4-dev.4+gabc1234
+//	v1.3.0-rc.1-4-gabc1234  1.3.0-rc.1.dev.4+gabc1234
+//	v1.2.3-4-gabc1234-dirty 1.2.4-dev.4+gabc1234.dirty
+//	v1.2.3-dirty            1.2.3-dirty
+//
+// The tag must be a semantic version, see Parse. Output without a tag
+// returns an error.
+func (d Describe) SemVer() (Version, error) {
+	if d.Tag == "" {
+		return Version{}, invalid(d.String(), "no tag to derive a version from")
+	}
+	tag, err := Parse(d.Tag)
+	if err != nil {
+		return Version{}, err
+	}
+	v := Version{Major: tag.Major, Minor: tag.Minor, Patch: tag.Patch, Prerelease: tag.Prerelease}
+	if d.Distance > 0 {
+		if len(v.Prerelease) == 0 {
+			// a release tag: the commits lead to the next patch release
+			v.Patch++
+		}
+		v.Prerelease = append(append([]Identifier(nil), v.Prerelease...), "dev", Identifier(strconv.Itoa(d.Distance)))
+	}
+	if d.ShortHash != "" && d.Distance > 0 {
+		v.Build = append(v.Build, "g"+d.ShortHash)
+	}
+	switch {
+	case d.Dirty && d.Distance == 0 && len(v.Prerelease) == 0:
+		// a dirty release build must not compare equal to the release
+		v.Prerelease = []Identifier{"dirty"}
+	case d.Dirty:
+		v.Build = append(v.Build, "dirty")
+	}
+	v.Prefix = v.PrereleaseString()
+	v.Raw = v.String()
+	return v, nil
+}
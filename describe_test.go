@@ -0,0 +1,120 @@
+package version
+
+import "testing"
+
+func TestParseDescribe(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Describe
+		ok   bool
+	}{
+		{"v1.2.3-4-gabc1234", Describe{Tag: "v1.2.3", Distance: 4, ShortHash: "abc1234"}, true},
+		{"v1.2.3-4-gabc1234-dirty", Describe{Tag: "v1.2.3", Distance: 4, ShortHash: "abc1234", Dirty: true}, true},
+		{"release-2024-10-gdeadbeef0", Describe{Tag: "release-2024", Distance: 10, ShortHash: "deadbeef0"}, true},
+		{"v2.0.0-rc.1-1-g0123456", Describe{Tag: "v2.0.0-rc.1", Distance: 1, ShortHash: "0123456"}, true},
+		{"abc1234", Describe{ShortHash: "abc1234"}, true},
+		{"abc1234-dirty", Describe{ShortHash: "abc1234", Dirty: true}, true},
+		{"v1.2.3-dirty", Describe{}, false},
+		{"1.2.3-dirty", Describe{}, false},
+		{"v1.2.3", Describe{}, false},
+		{"1.2.3-rc.1", Describe{}, false},
+		{"v1.2.3-4-gxyz1234", Describe{}, false},
+		{"v1.2.3-g1234567", Describe{}, false},
+		{"abc", Describe{}, false},
+		{"", Describe{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseDescribe(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseDescribe(%q) = %+v, %v, want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+		if ok && got.String() != tt.in {
+			t.Errorf("ParseDescribe(%q).String() = %q", tt.in, got.String())
+		}
+	}
+}
+
+func TestDescribe_SemVer(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"v1.2.3-4-gabc1234", "1.2.4-dev.4+gabc1234"},
+		{"v1.3.0-rc.1-4-gabc1234", "1.3.0-rc.1.dev.4+gabc1234"},
+		{"v1.2.3-4-gabc1234-dirty", "1.2.4-dev.4+gabc1234.dirty"},
+		{"v1.2.3+meta-2-gabc1234", "1.2.4-dev.2+gabc1234"},
+	}
+	for _, tt := range tests {
+		d, _ := ParseDescribe(tt.in)
+		v, err := d.SemVer()
+		if err != nil || v.String() != tt.want {
+			t.Errorf("SemVer(%q) = %q, %v, want %q", tt.in, v, err, tt.want)
+			continue
+		}
+		// the result must be a valid version that parses back to itself
+		if p, err := Parse(v.String()); err != nil || p.Compare(v) != 0 {
+			t.Errorf("Parse(%q) = %+v, %v", v, p, err)
+		}
+	}
+
+	// development builds sort between the tag and the next release
+	dev, _ := Describe{Tag: "v1.2.3", Distance: 4, ShortHash: "abc1234"}.SemVer()
+	if !dev.GreaterThan(MustParse("1.2.3")) || !dev.LessThan(MustParse("1.2.4")) {
+		t.Errorf("%s should sort between 1.2.3 and 1.2.4", dev)
+	}
+	rc, _ := Describe{Tag: "v1.3.0-rc.1", Distance: 4, ShortHash: "abc1234"}.SemVer()
+	if !rc.GreaterThan(MustParse("1.3.0-rc.1")) || !rc.LessThan(MustParse("1.3.0-rc.2")) {
+		t.Errorf("%s should sort between 1.3.0-rc.1 and 1.3.0-rc.2", rc)
+	}
+
+	dirty, err := Describe{Tag: "v1.2.3", Dirty: true}.SemVer()
+	if err != nil || dirty.String() != "1.2.3-dirty" || !dirty.LessThan(MustParse("1.2.3")) {
+		t.Errorf("dirty build of a tag = %s, %v, want 1.2.3-dirty below the release", dirty, err)
+	}
+
+	for _, d := range []Describe{{ShortHash: "abc1234"}, {Tag: "release-2024", Distance: 1, ShortHash: "abc1234"}} {
+		if _, err := d.SemVer(); err == nil {
+			t.Errorf("%+v.SemVer() should fail", d)
+		}
+	}
+}
+
+func TestSetVersion_DirtyTag(t *testing.T) {
+	// go-version ldflags --dirty on a tagged commit
+	i := New(WithVersion("1.2.3-dirty"))
+	v := i.Get()
+	if !v.IsPrerelease() || !v.LessThan(MustParse("1.2.3")) {
+		t.Errorf("Get() = %s, want a prerelease below 1.2.3", v)
+	}
+	if !i.Git().Describe.IsZero() {
+		t.Errorf("Describe = %+v, want none", i.Git().Describe)
+	}
+}
+
+func TestSetVersion_Describe(t *testing.T) {
+	i := New()
+	if err := i.SetVersion("v1.2.3-4-gabc1234"); err != nil {
+		t.Fatal(err)
+	}
+	v := i.Get()
+	if v.String() != "1.2.4-dev.4+gabc1234" || v.Raw != "v1.2.3-4-gabc1234" {
+		t.Errorf("Get() = %q (raw %q)", v, v.Raw)
+	}
+	if d := i.Git().Describe; d.Tag != "v1.2.3" || d.Distance != 4 || d.ShortHash != "abc1234" {
+		t.Errorf("Git().Describe = %+v", d)
+	}
+
+	// an untagged commit has no version, but the hash is kept
+	if err := i.SetVersion("abc1234"); err == nil {
+		t.Error("SetVersion(hash) should fail")
+	}
+	if d := i.Git().Describe; d.ShortHash != "abc1234" || i.Get().Raw != "abc1234" {
+		t.Errorf("Git().Describe = %+v, Raw = %q", d, i.Get().Raw)
+	}
+
+	if err := i.SetVersion("1.2.3"); err != nil {
+		t.Fatal(err)
+	}
+	if d := i.Git().Describe; !d.IsZero() {
+		t.Errorf("a plain version should clear Describe, got %+v", d)
+	}
+}
@@ -0,0 +1,87 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// versionFileName is the name of the file searched by LoadFromDefaultLocations.
+const versionFileName = ".version"
+
+// executable and systemConfigDir are variables so tests can replace them.
+var (
+	executable      = os.Executable
+	systemConfigDir = "/etc"
+)
+
+// DefaultLocations returns the paths LoadFromDefaultLocations searches, in
+// order:
+//
+//  1. ./.version in the working directory
+//  2. .version next to the executable, after resolving symlinks
+//  3. $XDG_CONFIG_HOME/<app>/.version, or ~/.config/<app>/.version
+//  4. /etc/<app>/.version
+//
+// The last two require an application name, see SetAppInfo.
+func (i *Info) DefaultLocations() []string {
+	paths := []string{versionFileName}
+	if wd, err := os.Getwd(); err == nil {
+		paths[0] = filepath.Join(wd, versionFileName)
+	}
+	if exe, err := executable(); err == nil {
+		if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+			exe = resolved
+		}
+		paths = append(paths, filepath.Join(filepath.Dir(exe), versionFileName))
+	}
+
+	name := i.load().app.Name
+	if name == "" {
+		return paths
+	}
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configDir = filepath.Join(home, ".config")
+		}
+	}
+	if configDir != "" {
+		paths = append(paths, filepath.Join(configDir, name, versionFileName))
+	}
+	return append(paths, filepath.Join(systemConfigDir, name, versionFileName))
+}
+
+// LoadFromDefaultLocations loads the first .version file found in
+// DefaultLocations, see LoadFromFile, and returns its path. If there is none
+// it returns an error wrapping ErrUnavailable.
+func (i *Info) LoadFromDefaultLocations() (string, error) {
+	paths := i.DefaultLocations()
+	for _, path := range paths {
+		if st, err := os.Stat(path); err != nil || st.IsDir() {
+			continue
+		}
+		return path, i.LoadFromFile(path)
+	}
+	return "", fmt.Errorf("%w: no %s file in %v", ErrUnavailable, versionFileName, paths)
+}
+
+// AutoLoadDefaultLocations makes the Info call LoadFromDefaultLocations as
+// soon as it has an application name: immediately if SetAppInfo was already
+// called, otherwise from the SetAppInfo call that sets the name. Errors are
+// ignored; call LoadFromDefaultLocations directly to check them.
+func (i *Info) AutoLoadDefaultLocations() {
+	var named bool
+	i.update(func(s *state) {
+		s.autoLoad = true
+		named = s.app.Name != ""
+	})
+	if named {
+		_, _ = i.LoadFromDefaultLocations()
+	}
+}
+
+// WithDefaultLocations enables AutoLoadDefaultLocations.
+func WithDefaultLocations() Option {
+	return func(i *Info) { i.AutoLoadDefaultLocations() }
+}
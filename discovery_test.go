@@ -0,0 +1,165 @@
+package version
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// discoveryDirs points every default location into a temporary directory
+// and returns the working, executable, XDG config and system config dirs.
+func discoveryDirs(t *testing.T) (wd, exeDir, xdg, etc string) {
+	t.Helper()
+	root := t.TempDir()
+	wd, exeDir, xdg, etc = filepath.Join(root, "wd"), filepath.Join(root, "bin"), filepath.Join(root, "xdg"), filepath.Join(root, "etc")
+	for _, dir := range []string{wd, exeDir, xdg, etc} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(wd); err != nil {
+		t.Fatal(err)
+	}
+	oldExe, oldEtc := executable, systemConfigDir
+	t.Cleanup(func() {
+		_ = os.Chdir(oldWd)
+		executable, systemConfigDir = oldExe, oldEtc
+	})
+	executable = func() (string, error) { return filepath.Join(exeDir, "myapp"), nil }
+	systemConfigDir = etc
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	return wd, exeDir, xdg, etc
+}
+
+func writeVersionFile(t *testing.T, dir, ver string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ".version")
+	if err := os.WriteFile(path, []byte("VERSION="+ver+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaultLocations(t *testing.T) {
+	wd, exeDir, xdg, etc := discoveryDirs(t)
+
+	if got := New().DefaultLocations(); len(got) != 2 {
+		t.Errorf("without an app name only the working and executable dirs are searched, got %v", got)
+	}
+
+	got := New(WithAppInfo("myapp", "")).DefaultLocations()
+	want := []string{
+		filepath.Join(wd, ".version"),
+		filepath.Join(exeDir, ".version"),
+		filepath.Join(xdg, "myapp", ".version"),
+		filepath.Join(etc, "myapp", ".version"),
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DefaultLocations() = %v, want %v", got, want)
+	}
+	for n := range want {
+		// the working directory may itself be reached through a symlink
+		if filepath.Base(filepath.Dir(got[n])) != filepath.Base(filepath.Dir(want[n])) {
+			t.Errorf("DefaultLocations()[%d] = %q, want %q", n, got[n], want[n])
+		}
+	}
+}
+
+func TestDefaultLocations_HomeConfig(t *testing.T) {
+	discoveryDirs(t)
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("HOME", home)
+
+	got := New(WithAppInfo("myapp", "")).DefaultLocations()
+	if want := filepath.Join(home, ".config", "myapp", ".version"); got[2] != want {
+		t.Errorf("config location = %q, want %q", got[2], want)
+	}
+}
+
+func TestDefaultLocations_SymlinkedExecutable(t *testing.T) {
+	_, exeDir, _, _ := discoveryDirs(t)
+	target := filepath.Join(exeDir, "myapp")
+	if err := os.WriteFile(target, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "myapp")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	executable = func() (string, error) { return link, nil }
+
+	got := New().DefaultLocations()
+	resolved, _ := filepath.EvalSymlinks(exeDir)
+	if want := filepath.Join(resolved, ".version"); got[1] != want {
+		t.Errorf("executable location = %q, want %q", got[1], want)
+	}
+}
+
+func TestLoadFromDefaultLocations_Order(t *testing.T) {
+	_, exeDir, xdg, etc := discoveryDirs(t)
+	writeVersionFile(t, filepath.Join(etc, "myapp"), "4.0.0")
+	want := writeVersionFile(t, filepath.Join(xdg, "myapp"), "3.0.0")
+
+	i := New(WithAppInfo("myapp", ""))
+	path, err := i.LoadFromDefaultLocations()
+	if err != nil || path != want || i.Get().Raw != "3.0.0" {
+		t.Errorf("LoadFromDefaultLocations() = %q, %v, version %q; want %q", path, err, i.Get().Raw, want)
+	}
+
+	writeVersionFile(t, exeDir, "2.0.0")
+	i = New(WithAppInfo("myapp", ""))
+	if _, err := i.LoadFromDefaultLocations(); err != nil || i.Get().Raw != "2.0.0" {
+		t.Errorf("executable dir should win over config, got %q, %v", i.Get().Raw, err)
+	}
+
+	writeVersionFile(t, ".", "1.0.0")
+	i = New(WithAppInfo("myapp", ""))
+	if _, err := i.LoadFromDefaultLocations(); err != nil || i.Get().Raw != "1.0.0" {
+		t.Errorf("working dir should win, got %q, %v", i.Get().Raw, err)
+	}
+	if src := i.Provenance()[FieldVersion]; !src.IsFile() {
+		t.Errorf("version source = %q, want a file source", src)
+	}
+}
+
+func TestLoadFromDefaultLocations_None(t *testing.T) {
+	discoveryDirs(t)
+	path, err := New(WithAppInfo("myapp", "")).LoadFromDefaultLocations()
+	if path != "" || !errors.Is(err, ErrUnavailable) {
+		t.Errorf("LoadFromDefaultLocations() = %q, %v; want ErrUnavailable", path, err)
+	}
+}
+
+func TestAutoLoadDefaultLocations(t *testing.T) {
+	_, _, xdg, _ := discoveryDirs(t)
+	writeVersionFile(t, filepath.Join(xdg, "myapp"), "3.0.0")
+
+	i := New(WithDefaultLocations())
+	if i.Get().Raw != "" {
+		t.Fatalf("nothing should be loaded before the app name is set, got %q", i.Get().Raw)
+	}
+	i.SetAppInfo("myapp", "")
+	if i.Get().Raw != "3.0.0" {
+		t.Errorf("SetAppInfo should load the config file, got %q", i.Get().Raw)
+	}
+
+	i = New(WithAppInfo("myapp", ""), WithDefaultLocations())
+	if i.Get().Raw != "3.0.0" {
+		t.Errorf("enabling after the name is set should load immediately, got %q", i.Get().Raw)
+	}
+
+	i = New(WithAppInfo("myapp", ""))
+	if i.Get().Raw != "" {
+		t.Errorf("discovery should be opt-in, got %q", i.Get().Raw)
+	}
+}
@@ -0,0 +1,58 @@
+package version
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// EnvProvider returns a Provider for environment variables. It reads the
+// keys of the env file format (APP_NAME, APP_DESCRIPTION, VERSION,
+// GIT_COMMIT, GIT_BRANCH, GIT_REPO, GIT_DIRTY, BUILD_TIMESTAMP) with the
+// given prefix, e.g. MYAPP_VERSION for prefix "MYAPP_". Fields that are
+// still empty are taken from common CI variables:
+//
+//   - GitHub Actions: GITHUB_SHA, GITHUB_REF_NAME (a branch, or the version
+//     when GITHUB_REF_TYPE is "tag") and GITHUB_SERVER_URL/GITHUB_REPOSITORY
+//   - GitLab CI: CI_COMMIT_SHA, CI_COMMIT_TAG, CI_COMMIT_BRANCH and
+//     CI_PROJECT_URL
+//
+// Empty variables are treated as unset.
+func EnvProvider(prefix string) Provider {
+	return ProviderFunc(func(context.Context) (Partial, error) {
+		var f File
+		read := func(key string) {
+			if value := strings.TrimSpace(os.Getenv(prefix + key)); value != "" {
+				f.setKey(key, value)
+			}
+		}
+		for _, key := range envKeys {
+			read(key)
+		}
+		read(dirtyKey)
+
+		// GitHub Actions
+		f.setKey("GIT_COMMIT", os.Getenv("GITHUB_SHA"))
+		if ref := os.Getenv("GITHUB_REF_NAME"); os.Getenv("GITHUB_REF_TYPE") == "tag" {
+			f.setKey("VERSION", ref)
+		} else {
+			f.setKey("GIT_BRANCH", ref)
+		}
+		if server, repo := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"); server != "" && repo != "" {
+			f.setKey("GIT_REPO", strings.TrimSuffix(server, "/")+"/"+repo)
+		}
+
+		// GitLab CI
+		f.setKey("GIT_COMMIT", os.Getenv("CI_COMMIT_SHA"))
+		f.setKey("VERSION", os.Getenv("CI_COMMIT_TAG"))
+		f.setKey("GIT_BRANCH", os.Getenv("CI_COMMIT_BRANCH"))
+		f.setKey("GIT_REPO", os.Getenv("CI_PROJECT_URL"))
+		return f.partial(SourceEnv), nil
+	})
+}
+
+// LoadFromEnv loads version information from environment variables, filling
+// fields that are not set yet. See EnvProvider for the variables.
+func (i *Info) LoadFromEnv(prefix string) error {
+	return i.loadOne(EnvProvider(prefix))
+}
@@ -0,0 +1,135 @@
+package version
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// clearCIEnv unsets the CI variables EnvProvider reads, so tests behave the
+// same when run in CI.
+func clearCIEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range []string{
+		"GITHUB_SHA", "GITHUB_REF_NAME", "GITHUB_REF_TYPE", "GITHUB_SERVER_URL", "GITHUB_REPOSITORY",
+		"CI_COMMIT_SHA", "CI_COMMIT_TAG", "CI_COMMIT_BRANCH", "CI_PROJECT_URL",
+	} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestEnvProvider_Prefix(t *testing.T) {
+	clearCIEnv(t)
+	t.Setenv("MYAPP_VERSION", "1.2.3")
+	t.Setenv("MYAPP_GIT_COMMIT", "abc123")
+	t.Setenv("MYAPP_GIT_BRANCH", "main")
+	t.Setenv("MYAPP_GIT_REPO", "github.com/org/myapp")
+	t.Setenv("MYAPP_BUILD_TIMESTAMP", "2024-01-15T10:30:00Z")
+	t.Setenv("VERSION", "9.9.9")
+
+	p, err := EnvProvider("MYAPP_").Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Partial{
+		Source:    SourceEnv,
+		Version:   "1.2.3",
+		Commit:    "abc123",
+		Branch:    "main",
+		Repo:      "github.com/org/myapp",
+		Timestamp: "2024-01-15T10:30:00Z",
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("Load() = %+v, want %+v", p, want)
+	}
+}
+
+func TestEnvProvider_NoPrefix(t *testing.T) {
+	clearCIEnv(t)
+	t.Setenv("VERSION", "1.0.0")
+	p, _ := EnvProvider("").Load(context.Background())
+	if p.Version != "1.0.0" {
+		t.Errorf("Version = %q, want 1.0.0", p.Version)
+	}
+}
+
+func TestEnvProvider_GitHubActions(t *testing.T) {
+	clearCIEnv(t)
+	t.Setenv("GITHUB_SHA", "ghsha")
+	t.Setenv("GITHUB_REF_NAME", "feature/x")
+	t.Setenv("GITHUB_REF_TYPE", "branch")
+	t.Setenv("GITHUB_SERVER_URL", "https://github.com")
+	t.Setenv("GITHUB_REPOSITORY", "org/myapp")
+
+	p, _ := EnvProvider("MYAPP_").Load(context.Background())
+	if p.Commit != "ghsha" || p.Branch != "feature/x" || p.Version != "" || p.Repo != "https://github.com/org/myapp" {
+		t.Errorf("Load() = %+v", p)
+	}
+
+	t.Setenv("GITHUB_REF_NAME", "v1.4.0")
+	t.Setenv("GITHUB_REF_TYPE", "tag")
+	p, _ = EnvProvider("MYAPP_").Load(context.Background())
+	if p.Version != "v1.4.0" || p.Branch != "" {
+		t.Errorf("tag build: Load() = %+v", p)
+	}
+}
+
+func TestEnvProvider_GitLabCI(t *testing.T) {
+	clearCIEnv(t)
+	t.Setenv("CI_COMMIT_SHA", "glsha")
+	t.Setenv("CI_COMMIT_TAG", "v2.0.0")
+	t.Setenv("CI_PROJECT_URL", "https://gitlab.com/org/myapp")
+
+	p, _ := EnvProvider("").Load(context.Background())
+	if p.Commit != "glsha" || p.Version != "v2.0.0" || p.Repo != "https://gitlab.com/org/myapp" {
+		t.Errorf("Load() = %+v", p)
+	}
+}
+
+func TestEnvProvider_PrefixBeforeCI(t *testing.T) {
+	clearCIEnv(t)
+	t.Setenv("GITHUB_SHA", "ghsha")
+	t.Setenv("MYAPP_GIT_COMMIT", "explicit")
+
+	p, _ := EnvProvider("MYAPP_").Load(context.Background())
+	if p.Commit != "explicit" {
+		t.Errorf("Commit = %q, prefixed variable should win over CI variables", p.Commit)
+	}
+}
+
+func TestEnvProvider_Dirty(t *testing.T) {
+	clearCIEnv(t)
+	for value, want := range map[string]string{"true": "true", "1": "true", "false": "false", "0": "false", "": "", "maybe": ""} {
+		t.Setenv("MYAPP_GIT_DIRTY", value)
+		if p, _ := EnvProvider("MYAPP_").Load(context.Background()); p.Dirty != want {
+			t.Errorf("GIT_DIRTY=%q: Dirty = %q, want %q", value, p.Dirty, want)
+		}
+	}
+
+	// a clean tree reported by the environment is recorded as known
+	t.Setenv("MYAPP_GIT_DIRTY", "false")
+	i := New()
+	if err := i.LoadFromEnv("MYAPP_"); err != nil {
+		t.Fatal(err)
+	}
+	if i.Git().Dirty || i.Provenance()[FieldDirty] != SourceEnv {
+		t.Errorf("GIT_DIRTY=false: Dirty %v from %q", i.Git().Dirty, i.Provenance()[FieldDirty])
+	}
+}
+
+func TestLoadFromEnv_DoesNotOverwrite(t *testing.T) {
+	clearCIEnv(t)
+	t.Setenv("MYAPP_VERSION", "2.0.0")
+	t.Setenv("MYAPP_GIT_COMMIT", "envcommit")
+
+	i := New(WithVersion("1.0.0"))
+	if err := i.LoadFromEnv("MYAPP_"); err != nil {
+		t.Fatal(err)
+	}
+	if i.Get().Raw != "1.0.0" || i.Git().Commit != "envcommit" {
+		t.Errorf("LoadFromEnv() = %q %q", i.Get().Raw, i.Git().Commit)
+	}
+	if i.Provenance()[FieldCommit] != SourceEnv {
+		t.Errorf("commit source = %q, want env", i.Provenance()[FieldCommit])
+	}
+}
@@ -0,0 +1,708 @@
+package version
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format is a version file format.
+type Format string
+
+// Supported version file formats. FormatEnv is the original key=value
+// .version format.
+const (
+	FormatEnv  Format = "env"
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// Formats lists the supported version file formats.
+var Formats = []Format{FormatEnv, FormatJSON, FormatYAML, FormatTOML}
+
+// ParseFormat returns the format with the given name; "yml" is accepted for
+// YAML.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatEnv, FormatJSON, FormatYAML, FormatTOML:
+		return f, nil
+	case "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unknown version file format %q (want env, json, yaml or toml)", name)
+}
+
+// FormatOf detects the format of a version file from its extension: .json,
+// .yaml, .yml and .toml. Anything else, including .version, is FormatEnv.
+func FormatOf(name string) Format {
+	switch strings.ToLower(path.Ext(strings.ReplaceAll(name, `\`, "/"))) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatEnv
+}
+
+// ErrInvalidFile is returned (wrapped in a *FileError) when a version file
+// cannot be parsed.
+var ErrInvalidFile = errors.New("invalid version file")
+
+// FileError reports a version file that could not be parsed.
+type FileError struct {
+	Format Format
+	// 1-based line number, 0 if unknown
+	Line int
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("invalid %s version file: %v", e.Format, e.Err)
+	}
+	return fmt.Sprintf("invalid %s version file: line %d: %v", e.Format, e.Line, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// Is allows errors.Is(err, ErrInvalidFile).
+func (e *FileError) Is(target error) bool {
+	return target == ErrInvalidFile
+}
+
+// File is the content of a version file. The same schema is used by every
+// format; in JSON:
+//
+//	{
+//	  "app": {"name": "myapp", "description": "My application"},
+//	  "version": "1.2.3",
+//	  "git": {"commit": "abc123", "branch": "main", "repo": "github.com/org/myapp", "dirty": false},
+//	  "build_timestamp": "2024-01-15T10:30:00Z",
+//	  "extra": {"pipeline": "1234"}
+//	}
+//
+// YAML uses the same keys and nesting, TOML uses [app], [git] and [extra]
+// tables. The env format uses the keys APP_NAME, APP_DESCRIPTION, VERSION,
+// GIT_COMMIT, GIT_BRANCH, GIT_REPO, GIT_DIRTY and BUILD_TIMESTAMP; any other
+// key is an extra label.
+type File struct {
+	AppName        string
+	AppDescription string
+	Version        string
+	Commit         string
+	Branch         string
+	Repo           string
+	// "true" or "false" if known whether the working tree had uncommitted
+	// changes
+	Dirty          string
+	BuildTimestamp string
+	// arbitrary key/value labels, e.g. a CI pipeline ID
+	Extra map[string]string
+}
+
+// ParseFile parses version file content in the given format.
+func ParseFile(data []byte, format Format) (File, error) {
+	switch format {
+	case FormatEnv:
+		return parseEnvFile(data)
+	case FormatJSON:
+		return parseJSONFile(data)
+	case FormatYAML:
+		return parseYAMLFile(data)
+	case FormatTOML:
+		return parseTOMLFile(data)
+	}
+	_, err := ParseFormat(string(format))
+	return File{}, err
+}
+
+// Encode renders f in the given format. Empty fields are omitted.
+func (f File) Encode(format Format) ([]byte, error) {
+	switch format {
+	case FormatEnv:
+		return f.encodeEnv(), nil
+	case FormatJSON:
+		return f.encodeJSON()
+	case FormatYAML:
+		return f.encodeYAML(), nil
+	case FormatTOML:
+		return f.encodeTOML(), nil
+	}
+	_, err := ParseFormat(string(format))
+	return nil, err
+}
+
+// partial converts f for merging into an Info.
+func (f File) partial(src Source) Partial {
+	return Partial{
+		Source:         src,
+		AppName:        f.AppName,
+		AppDescription: f.AppDescription,
+		Version:        f.Version,
+		Timestamp:      f.BuildTimestamp,
+		Commit:         f.Commit,
+		Branch:         f.Branch,
+		Repo:           f.Repo,
+		Dirty:          f.Dirty,
+		Extra:          f.Extra,
+	}
+}
+
+// parseDirty normalizes a dirty flag to "true" or "false", "" if it is not a
+// boolean.
+func parseDirty(value string) string {
+	dirty, err := strconv.ParseBool(value)
+	if err != nil {
+		return ""
+	}
+	return strconv.FormatBool(dirty)
+}
+
+// formatDirty renders a dirty flag of GitInfo, which cannot tell a clean tree
+// from an unknown one, so only a dirty tree is set.
+func formatDirty(dirty bool) string {
+	if dirty {
+		return "true"
+	}
+	return ""
+}
+
+// envKeys are the keys of the env format in output order. With a prefix
+// they are also read from the environment, see EnvProvider.
+var envKeys = []string{"APP_NAME", "APP_DESCRIPTION", "VERSION", "GIT_COMMIT", "GIT_BRANCH", "GIT_REPO", "BUILD_TIMESTAMP"}
+
+// dirtyKey is the env format key of File.Dirty.
+const dirtyKey = "GIT_DIRTY"
+
+// envField returns the field for an env format key, nil for unknown keys.
+func (f *File) envField(key string) *string {
+	switch key {
+	case "APP_NAME":
+		return &f.AppName
+	case "APP_DESCRIPTION":
+		return &f.AppDescription
+	case "VERSION":
+		return &f.Version
+	case "GIT_COMMIT":
+		return &f.Commit
+	case "GIT_BRANCH":
+		return &f.Branch
+	case "GIT_REPO":
+		return &f.Repo
+	case "BUILD_TIMESTAMP":
+		return &f.BuildTimestamp
+	}
+	return nil
+}
+
+// setKey sets the field for an env format key unless it is already set, so
+// the first occurrence of a key wins.
+func (f *File) setKey(key, value string) {
+	if key == dirtyKey {
+		if f.Dirty == "" {
+			f.Dirty = parseDirty(value)
+		}
+		return
+	}
+	if field := f.envField(key); field != nil && *field == "" {
+		*field = value
+	}
+}
+
+// setEnvKey is like setKey but stores unknown keys as extra labels.
+func (f *File) setEnvKey(key, value string) {
+	if key == dirtyKey || f.envField(key) != nil {
+		f.setKey(key, value)
+		return
+	}
+	if _, ok := f.Extra[key]; ok {
+		return
+	}
+	if f.Extra == nil {
+		f.Extra = map[string]string{}
+	}
+	f.Extra[key] = value
+}
+
+// set sets a field by its section and key in the structured formats, e.g.
+// ("git", "commit"). Unknown keys are ignored.
+func (f *File) set(section, key, value string) {
+	var field *string
+	switch section + "." + key {
+	case "app.name":
+		field = &f.AppName
+	case "app.description":
+		field = &f.AppDescription
+	case ".version":
+		field = &f.Version
+	case "git.commit":
+		field = &f.Commit
+	case "git.branch":
+		field = &f.Branch
+	case "git.repo":
+		field = &f.Repo
+	case "git.dirty":
+		f.Dirty = parseDirty(value)
+		return
+	case ".build_timestamp":
+		field = &f.BuildTimestamp
+	}
+	if section == "extra" {
+		if f.Extra == nil {
+			f.Extra = map[string]string{}
+		}
+		f.Extra[key] = value
+		return
+	}
+	if field != nil {
+		*field = value
+	}
+}
+
+// parseEnvFile parses the key=value format. Blank lines, lines starting with
+// '#' and lines without '=' are ignored; unknown keys are extra labels.
+func parseEnvFile(data []byte) (File, error) {
+	var f File
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		if key := strings.TrimSpace(parts[0]); key != "" {
+			f.setEnvKey(key, strings.TrimSpace(parts[1]))
+		}
+	}
+	return f, scanner.Err()
+}
+
+func (f File) encodeEnv() []byte {
+	var sb strings.Builder
+	sb.WriteString("# Generated by go-version\n")
+	for _, key := range envKeys {
+		if value := *f.envField(key); value != "" {
+			fmt.Fprintf(&sb, "%s=%s\n", key, value)
+		}
+	}
+	if f.Dirty != "" {
+		fmt.Fprintf(&sb, "%s=%s\n", dirtyKey, f.Dirty)
+	}
+	for _, key := range sortedKeys(f.Extra) {
+		fmt.Fprintf(&sb, "%s=%s\n", key, f.Extra[key])
+	}
+	return []byte(sb.String())
+}
+
+// jsonFile is the JSON encoding of File.
+type jsonFile struct {
+	App            *jsonApp          `json:"app,omitempty"`
+	Version        string            `json:"version,omitempty"`
+	Git            *jsonGit          `json:"git,omitempty"`
+	BuildTimestamp string            `json:"build_timestamp,omitempty"`
+	Extra          map[string]string `json:"extra,omitempty"`
+}
+
+type jsonApp struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type jsonGit struct {
+	Commit string `json:"commit,omitempty"`
+	Branch string `json:"branch,omitempty"`
+	Repo   string `json:"repo,omitempty"`
+	Dirty  *bool  `json:"dirty,omitempty"`
+}
+
+func parseJSONFile(data []byte) (File, error) {
+	var j jsonFile
+	if err := json.Unmarshal(data, &j); err != nil {
+		return File{}, &FileError{Format: FormatJSON, Err: err}
+	}
+	f := File{Version: j.Version, BuildTimestamp: j.BuildTimestamp, Extra: j.Extra}
+	if j.App != nil {
+		f.AppName, f.AppDescription = j.App.Name, j.App.Description
+	}
+	if j.Git != nil {
+		f.Commit, f.Branch, f.Repo = j.Git.Commit, j.Git.Branch, j.Git.Repo
+		if j.Git.Dirty != nil {
+			f.Dirty = strconv.FormatBool(*j.Git.Dirty)
+		}
+	}
+	return f, nil
+}
+
+func (f File) encodeJSON() ([]byte, error) {
+	j := jsonFile{Version: f.Version, BuildTimestamp: f.BuildTimestamp, Extra: f.Extra}
+	if f.AppName != "" || f.AppDescription != "" {
+		j.App = &jsonApp{Name: f.AppName, Description: f.AppDescription}
+	}
+	if f.Commit != "" || f.Branch != "" || f.Repo != "" || f.Dirty != "" {
+		j.Git = &jsonGit{Commit: f.Commit, Branch: f.Branch, Repo: f.Repo}
+		if dirty, err := strconv.ParseBool(f.Dirty); err == nil {
+			j.Git.Dirty = &dirty
+		}
+	}
+	b, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// parseYAMLFile parses the subset of YAML used by the schema: scalar values
+// in top-level keys and in one level of nested mappings.
+func parseYAMLFile(data []byte) (File, error) {
+	var f File
+	section := ""
+	for n, raw := range strings.Split(string(data), "\n") {
+		fail := func(format string, args ...interface{}) (File, error) {
+			return File{}, &FileError{Format: FormatYAML, Line: n + 1, Err: fmt.Errorf(format, args...)}
+		}
+		line := strings.TrimRight(raw, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return fail("tabs are not allowed for indentation")
+		}
+		nested := strings.HasPrefix(line, " ")
+		if nested && section == "" {
+			return fail("unexpected indentation")
+		}
+
+		key, rest, err := splitYAMLKey(trimmed)
+		if err != nil {
+			return fail("%v", err)
+		}
+		value, err := yamlScalar(rest)
+		if err != nil {
+			return fail("%v", err)
+		}
+		switch {
+		case nested:
+			f.set(section, key, value)
+		case strings.TrimSpace(rest) == "" || strings.HasPrefix(strings.TrimSpace(rest), "#"):
+			// start of a nested mapping
+			section = key
+		default:
+			section = ""
+			f.set("", key, value)
+		}
+	}
+	return f, nil
+}
+
+// splitYAMLKey splits "key: value" into the key and the raw value.
+func splitYAMLKey(line string) (key, rest string, err error) {
+	if strings.HasPrefix(line, "- ") || line == "-" {
+		return "", "", errors.New("lists are not supported")
+	}
+	if line[0] == '"' || line[0] == '\'' {
+		key, rest, err = unquotePrefix(line)
+		if err != nil {
+			return "", "", err
+		}
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", fmt.Errorf("missing ':' after key %q", key)
+		}
+		return key, rest[1:], nil
+	}
+	i := strings.Index(line, ":")
+	if i <= 0 || (i+1 < len(line) && line[i+1] != ' ') {
+		return "", "", fmt.Errorf("expected 'key: value', got %q", line)
+	}
+	return strings.TrimSpace(line[:i]), line[i+1:], nil
+}
+
+// yamlScalar parses a plain, single- or double-quoted scalar with an optional
+// trailing comment. null and ~ are empty.
+func yamlScalar(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		value, rest, err := unquotePrefix(s)
+		if err != nil {
+			return "", err
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after quoted value", rest)
+		}
+		return value, nil
+	}
+	if strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[") || strings.HasPrefix(s, "|") || strings.HasPrefix(s, ">") {
+		return "", fmt.Errorf("unsupported value %q", s)
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	if s == "~" || s == "null" {
+		return "", nil
+	}
+	return s, nil
+}
+
+// parseTOMLFile parses the subset of TOML used by the schema: string, number,
+// boolean and date values in the root table and in [section] tables.
+func parseTOMLFile(data []byte) (File, error) {
+	var f File
+	section := ""
+	for n, raw := range strings.Split(string(data), "\n") {
+		fail := func(format string, args ...interface{}) (File, error) {
+			return File{}, &FileError{Format: FormatTOML, Line: n + 1, Err: fmt.Errorf(format, args...)}
+		}
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			end := strings.Index(line, "]")
+			if end < 0 || strings.HasPrefix(line, "[[") {
+				return fail("invalid table header %q", line)
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return fail("unexpected %q after table header", rest)
+			}
+			section = strings.TrimSpace(line[1:end])
+			continue
+		}
+
+		key, rest, err := tomlKey(line)
+		if err != nil {
+			return fail("%v", err)
+		}
+		value, err := tomlValue(rest)
+		if err != nil {
+			return fail("%v", err)
+		}
+		sec := section
+		// dotted keys such as git.commit at the root
+		if sec == "" && len(key) == 2 {
+			sec, key = key[0], key[1:]
+		}
+		if len(key) != 1 {
+			return fail("unsupported key %q", strings.Join(key, "."))
+		}
+		f.set(sec, key[0], value)
+	}
+	return f, nil
+}
+
+// tomlKey parses a bare, quoted or dotted key up to '='.
+func tomlKey(line string) (key []string, rest string, err error) {
+	rest = line
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		var part string
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			if part, rest, err = unquotePrefix(rest); err != nil {
+				return nil, "", err
+			}
+		} else {
+			end := strings.IndexAny(rest, ".= \t")
+			if end <= 0 {
+				return nil, "", fmt.Errorf("expected 'key = value', got %q", line)
+			}
+			part, rest = rest[:end], rest[end:]
+		}
+		key = append(key, part)
+		rest = strings.TrimLeft(rest, " \t")
+		switch {
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+		case strings.HasPrefix(rest, "="):
+			return key, rest[1:], nil
+		default:
+			return nil, "", fmt.Errorf("expected 'key = value', got %q", line)
+		}
+	}
+}
+
+// tomlValue parses a string value, or returns numbers, booleans and dates
+// as written. Arrays and inline tables are not supported.
+func tomlValue(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", errors.New("missing value")
+	}
+	if strings.HasPrefix(s, `"""`) || strings.HasPrefix(s, "'''") {
+		return "", errors.New("multi-line strings are not supported")
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		value, rest, err := unquotePrefix(s)
+		if err != nil {
+			return "", err
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after value", rest)
+		}
+		return value, nil
+	}
+	if s[0] == '[' || s[0] == '{' {
+		return "", fmt.Errorf("unsupported value %q", s)
+	}
+	if i := strings.Index(s, "#"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s, nil
+}
+
+// unquotePrefix parses the double- or single-quoted string at the start of s
+// and returns it with the remaining text. Double-quoted strings support the
+// escapes shared by YAML, TOML and Go; single-quoted strings have no escapes
+// except a doubled quote (YAML).
+func unquotePrefix(s string) (value, rest string, err error) {
+	if s[0] == '\'' {
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				sb.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			return sb.String(), s[i+1:], nil
+		}
+		return "", "", errors.New("unterminated string")
+	}
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", fmt.Errorf("invalid string %s", s[:i+1])
+			}
+			return value, s[i+1:], nil
+		}
+	}
+	return "", "", errors.New("unterminated string")
+}
+
+// quote renders s as a double-quoted string valid in YAML, TOML and JSON.
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f || r == utf8.RuneError:
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// quoteKey renders a key bare if possible, quoted otherwise.
+func quoteKey(key string) string {
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return quote(key)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
+
+// fileSection is a group of fields in the structured formats.
+type fileSection struct {
+	name string
+	// keys and values, values rendered as YAML and TOML scalars
+	fields [][2]string
+}
+
+// sections returns the non-empty fields of f grouped for the structured
+// formats; the root section has no name.
+func (f File) sections() []fileSection {
+	var out []fileSection
+	add := func(name string, fields ...[2]string) {
+		var nonEmpty [][2]string
+		for _, kv := range fields {
+			if kv[1] != "" || name == "extra" {
+				nonEmpty = append(nonEmpty, [2]string{kv[0], quote(kv[1])})
+			}
+		}
+		if name == "git" && parseDirty(f.Dirty) != "" {
+			nonEmpty = append(nonEmpty, [2]string{"dirty", parseDirty(f.Dirty)})
+		}
+		if len(nonEmpty) > 0 {
+			out = append(out, fileSection{name, nonEmpty})
+		}
+	}
+	add("", [2]string{"version", f.Version}, [2]string{"build_timestamp", f.BuildTimestamp})
+	add("app", [2]string{"name", f.AppName}, [2]string{"description", f.AppDescription})
+	add("git", [2]string{"commit", f.Commit}, [2]string{"branch", f.Branch}, [2]string{"repo", f.Repo})
+
+	extra := make([][2]string, 0, len(f.Extra))
+	for _, k := range sortedKeys(f.Extra) {
+		extra = append(extra, [2]string{k, f.Extra[k]})
+	}
+	add("extra", extra...)
+	return out
+}
+
+func (f File) encodeYAML() []byte {
+	var sb strings.Builder
+	sb.WriteString("# Generated by go-version\n")
+	for _, sec := range f.sections() {
+		indent := ""
+		if sec.name != "" {
+			fmt.Fprintf(&sb, "%s:\n", sec.name)
+			indent = "  "
+		}
+		for _, kv := range sec.fields {
+			fmt.Fprintf(&sb, "%s%s: %s\n", indent, quoteKey(kv[0]), kv[1])
+		}
+	}
+	return []byte(sb.String())
+}
+
+func (f File) encodeTOML() []byte {
+	var sb strings.Builder
+	sb.WriteString("# Generated by go-version\n")
+	for _, sec := range f.sections() {
+		if sec.name != "" {
+			fmt.Fprintf(&sb, "\n[%s]\n", sec.name)
+		}
+		for _, kv := range sec.fields {
+			fmt.Fprintf(&sb, "%s = %s\n", quoteKey(kv[0]), kv[1])
+		}
+	}
+	return []byte(sb.String())
+}
@@ -0,0 +1,245 @@
+package version
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var fullFile = File{
+	AppName:        "myapp",
+	AppDescription: `My "quoted" app: with # signs`,
+	Version:        "1.2.3-rc.1+build.5",
+	Commit:         "abc123",
+	Branch:         "feature/x",
+	Repo:           "https://github.com/org/myapp",
+	Dirty:          "true",
+	BuildTimestamp: "2024-01-15T10:30:00Z",
+	Extra:          map[string]string{"pipeline": "1234", "release.channel": "beta", "note": "line\nbreak\ttab"},
+}
+
+func TestFile_RoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatYAML, FormatTOML} {
+		data, err := fullFile.Encode(format)
+		if err != nil {
+			t.Fatalf("Encode(%s) error = %v", format, err)
+		}
+		got, err := ParseFile(data, format)
+		if err != nil {
+			t.Fatalf("ParseFile(%s) error = %v\n%s", format, err, data)
+		}
+		if !reflect.DeepEqual(got, fullFile) {
+			t.Errorf("%s round trip = %+v, want %+v\n%s", format, got, fullFile, data)
+		}
+	}
+}
+
+func TestFile_RoundTripEnv(t *testing.T) {
+	want := fullFile
+	want.AppDescription = "My app"
+	want.Extra = nil
+	data, err := want.Encode(FormatEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseFile(data, FormatEnv)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("env round trip = %+v, %v\n%s", got, err, data)
+	}
+}
+
+func TestFile_EncodeOmitsEmpty(t *testing.T) {
+	f := File{Version: "1.0.0"}
+	for _, format := range Formats {
+		data, _ := f.Encode(format)
+		for _, key := range []string{"app", "git", "extra", "APP_NAME", "GIT_COMMIT"} {
+			if strings.Contains(string(data), key) {
+				t.Errorf("%s output should omit empty %q:\n%s", format, key, data)
+			}
+		}
+	}
+}
+
+func TestParseFile_YAML(t *testing.T) {
+	data := `# deploy metadata
+---
+version: 1.2.3 # released
+app:
+  name: 'my''app'
+  description: "A \"service\""
+git:
+  commit: abc123
+  repo: https://github.com/org/myapp
+build_timestamp: ~
+extra:
+  "ticket.id": OPS-42
+unknown: ignored
+`
+	got, err := ParseFile([]byte(data), FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := File{
+		AppName:        "my'app",
+		AppDescription: `A "service"`,
+		Version:        "1.2.3",
+		Commit:         "abc123",
+		Repo:           "https://github.com/org/myapp",
+		Extra:          map[string]string{"ticket.id": "OPS-42"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFile() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFile_TOML(t *testing.T) {
+	data := `version = "1.2.3" # released
+build_timestamp = 2024-01-15T10:30:00Z
+git.branch = 'main'
+
+[app]
+name = "myapp"
+
+[git]
+commit = "abc123"
+
+[extra]
+"release.channel" = "beta"
+build = 42
+`
+	got, err := ParseFile([]byte(data), FormatTOML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := File{
+		AppName:        "myapp",
+		Version:        "1.2.3",
+		Commit:         "abc123",
+		Branch:         "main",
+		BuildTimestamp: "2024-01-15T10:30:00Z",
+		Extra:          map[string]string{"release.channel": "beta", "build": "42"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseFile() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFile_Errors(t *testing.T) {
+	tests := []struct {
+		format Format
+		data   string
+		line   int
+	}{
+		{FormatJSON, `{"version": `, 0},
+		{FormatJSON, `{"version": 1}`, 0},
+		{FormatYAML, "version: 1\n  nested: x\n", 2},
+		{FormatYAML, "extra:\n  - item\n", 2},
+		{FormatYAML, "version: \"unterminated\n", 1},
+		{FormatYAML, "app:\n\tname: x\n", 2},
+		{FormatTOML, "version = \"1\"\n[[array]]\n", 2},
+		{FormatTOML, "version\n", 1},
+		{FormatTOML, "version = [1, 2]\n", 1},
+		{FormatTOML, "a.b.c = \"x\"\n", 1},
+	}
+	for _, tt := range tests {
+		_, err := ParseFile([]byte(tt.data), tt.format)
+		var fe *FileError
+		if !errors.Is(err, ErrInvalidFile) || !errors.As(err, &fe) {
+			t.Errorf("ParseFile(%s, %q) error = %v, want ErrInvalidFile", tt.format, tt.data, err)
+			continue
+		}
+		if fe.Line != tt.line {
+			t.Errorf("ParseFile(%s, %q) line = %d, want %d", tt.format, tt.data, fe.Line, tt.line)
+		}
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]Format{
+		".version":           FormatEnv,
+		"build/version.json": FormatJSON,
+		"v.YAML":             FormatYAML,
+		"v.yml":              FormatYAML,
+		"v.toml":             FormatTOML,
+		"v.txt":              FormatEnv,
+	}
+	for name, want := range tests {
+		if got := FormatOf(name); got != want {
+			t.Errorf("FormatOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) should fail")
+	}
+	if _, err := ParseFile(nil, "xml"); err == nil {
+		t.Error("ParseFile with an unknown format should fail")
+	}
+}
+
+func TestLoadFromFile_DetectsFormat(t *testing.T) {
+	dir := t.TempDir()
+	for _, format := range []Format{FormatJSON, FormatYAML, FormatTOML} {
+		data, _ := fullFile.Encode(format)
+		path := filepath.Join(dir, "version."+string(format))
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		i := New()
+		if err := i.LoadFromFile(path); err != nil {
+			t.Fatalf("LoadFromFile(%s) error = %v", path, err)
+		}
+		if i.Get().Raw != fullFile.Version || i.App().Name != "myapp" || i.Git().Branch != "feature/x" || i.Build().Timestamp.IsZero() {
+			t.Errorf("LoadFromFile(%s) = %+v %+v %+v", path, i.Get(), i.App(), i.Build())
+		}
+	}
+}
+
+func TestLoadFromFile_Clean(t *testing.T) {
+	dir := t.TempDir()
+	clean := File{Commit: "abc123", Dirty: "false"}
+	for _, format := range Formats {
+		data, _ := clean.Encode(format)
+		if got, err := ParseFile(data, format); err != nil || got.Dirty != "false" {
+			t.Errorf("ParseFile(%s) Dirty = %q, %v, want false\n%s", format, got.Dirty, err, data)
+		}
+		path := filepath.Join(dir, "version."+string(format))
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+		// a clean tree recorded in the file wins over a later provider
+		i := New()
+		err := i.Resolve(FileProvider(path), staticProvider(Partial{Source: SourceGit, Dirty: "true"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.Git().Dirty || i.Provenance()[FieldDirty] != FileSource(path) {
+			t.Errorf("%s: Dirty %v from %q, want clean from the file", format, i.Git().Dirty, i.Provenance()[FieldDirty])
+		}
+	}
+}
+
+func TestLoadFromFile_InvalidFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "version.json")
+	if err := os.WriteFile(path, []byte("VERSION=1.0.0"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	i := New()
+	if err := i.LoadFromFile(path); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("LoadFromFile() error = %v, want ErrInvalidFile", err)
+	}
+	if i.Get().Raw != "" {
+		t.Error("an invalid file should not change the Info")
+	}
+}
+
+func TestFile_EnvExtraRoundTrip(t *testing.T) {
+	f := File{Version: "1.0.0", Extra: map[string]string{"PIPELINE": "1234", "release.channel": "beta"}}
+	data, _ := f.Encode(FormatEnv)
+	got, err := ParseFile(data, FormatEnv)
+	if err != nil || got.Extra["PIPELINE"] != "1234" || got.Extra["release.channel"] != "beta" {
+		t.Errorf("ParseFile() = %+v, %v\n%s", got, err, data)
+	}
+}
@@ -0,0 +1,418 @@
+package version
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Info holds the application, version, build and git metadata of one
+// component. The package-level functions operate on a default Info (see
+// Default) that is populated from ldflags and debug.ReadBuildInfo at init;
+// libraries that want to report their own version separately from the host
+// binary, and tests, can create independent instances with New.
+//
+// Info is safe for concurrent use. Every change publishes a new immutable
+// snapshot, so getters never block and are cheap enough for hot paths such as
+// per-request headers. Values returned by getters share slices and maps with
+// the snapshot and must not be modified.
+type Info struct {
+	// serializes writers, readers only load the snapshot
+	mu    sync.Mutex
+	state atomic.Pointer[state]
+}
+
+// state is an immutable snapshot of an Info. It is copied, modified and
+// swapped in by update, never changed after being published.
+type state struct {
+	app     AppInfo
+	version Version
+	build   BuildInfo
+	prov    Sources
+	// merge precedence per field for Resolve, FirstWins if unset
+	prec map[Field]Precedence
+	// load DefaultLocations once the app name is set
+	autoLoad bool
+}
+
+// emptyState is returned for an Info that has never been written.
+var emptyState = &state{}
+
+// load returns the current snapshot.
+func (i *Info) load() *state {
+	if s := i.state.Load(); s != nil {
+		return s
+	}
+	return emptyState
+}
+
+// update applies fn to a copy of the current snapshot and publishes it.
+func (i *Info) update(fn func(s *state)) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	next := *i.load()
+	next.prov = next.prov.clone()
+	fn(&next)
+	i.state.Store(&next)
+}
+
+// Option configures an Info created by New.
+type Option func(*Info)
+
+// WithAppInfo sets the application name and description.
+func WithAppInfo(name, description string) Option {
+	return func(i *Info) { i.SetAppInfo(name, description) }
+}
+
+// WithVersion sets the version, see SetVersion. An invalid version is kept
+// in Version.Raw only.
+func WithVersion(ver string) Option {
+	return func(i *Info) { _ = i.SetVersion(ver) }
+}
+
+// WithGitInfo sets the git commit, branch and repository.
+func WithGitInfo(commit, branch, repo string) Option {
+	return func(i *Info) { i.SetGitInfo(commit, branch, repo) }
+}
+
+// WithBuildInfo sets the build timestamp, see SetBuildInfo.
+func WithBuildInfo(timestamp string) Option {
+	return func(i *Info) { i.SetBuildInfo(timestamp) }
+}
+
+// WithChangelog sets the changelog text, see SetChangelog. Parse errors are
+// ignored; call SetChangelog to check them.
+func WithChangelog(changelog string) Option {
+	return func(i *Info) { _ = i.SetChangelog(changelog) }
+}
+
+// WithLdflags loads the package-level variables injected with -ldflags -X
+// (VersionInfo, GitCommit, GitBranch, GitRepo, GitDirty, BuildTimestamp,
+// ExtraInfo).
+func WithLdflags() Option {
+	return func(i *Info) { i.loadFromLdflags() }
+}
+
+// WithRuntimeBuildInfo fills empty fields from debug.ReadBuildInfo, i.e. the
+// module version and VCS settings recorded by the go command.
+func WithRuntimeBuildInfo() Option {
+	return func(i *Info) { i.loadFromBuildInfo() }
+}
+
+// New returns an empty Info configured by opts, applied in order.
+func New(opts ...Option) *Info {
+	i := &Info{}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// std is the default instance used by the package-level functions.
+var std = New()
+
+// Default returns the Info used by the package-level functions.
+func Default() *Info {
+	return std
+}
+
+// loadFromLdflags loads the injected package-level variables.
+func (i *Info) loadFromLdflags() {
+	_ = i.Resolve(LdflagsProvider())
+}
+
+// loadFromBuildInfo fills empty fields from runtime/debug.ReadBuildInfo.
+func (i *Info) loadFromBuildInfo() {
+	_ = i.Resolve(BuildInfoProvider())
+}
+
+// SetAppInfo sets the application name and description if not already set.
+// With AutoLoadDefaultLocations enabled, setting the name loads the first
+// .version file found in DefaultLocations.
+func (i *Info) SetAppInfo(name, description string) {
+	var named bool
+	i.update(func(s *state) {
+		unnamed := s.app.Name == ""
+		s.setAppInfo(name, description, SourceSetter)
+		named = unnamed && s.app.Name != "" && s.autoLoad
+	})
+	if named {
+		_, _ = i.LoadFromDefaultLocations()
+	}
+}
+
+// SetGitInfo set git details if no commit is set yet
+func (i *Info) SetGitInfo(commit, branch, repo string) {
+	i.update(func(s *state) { s.setGitInfo(commit, branch, repo, SourceSetter) })
+}
+
+// SetBuildInfo set build timestamp if not already set
+func (i *Info) SetBuildInfo(timestamp string) {
+	i.update(func(s *state) { s.setBuildInfo(timestamp, SourceSetter) })
+}
+
+// SetExtra sets an extra label if it is not already set.
+func (i *Info) SetExtra(key, value string) {
+	i.update(func(s *state) {
+		if _, ok := s.build.Extra[key]; !ok {
+			s.setExtra(key, value, SourceSetter)
+		}
+	})
+}
+
+// SetChangelog set application changelog. The text is parsed as Keep a
+// Changelog Markdown into App().Changes; a parse error is returned but the
+// raw text is kept.
+func (i *Info) SetChangelog(changelog string) error {
+	var err error
+	i.update(func(s *state) { err = s.setChangelog(changelog, SourceSetter) })
+	return err
+}
+
+// SetChangelogFromFile read changelog from a file, see SetChangelog
+func (i *Info) SetChangelogFromFile(path string) error {
+	if i.load().app.Changelog != "" {
+		return nil
+	}
+	b, err := os.ReadFile(path) // #nosec G304 -- reading user-specified version file
+	if err != nil {
+		return err
+	}
+	i.update(func(s *state) { err = s.setChangelog(string(b), FileSource(path)) })
+	return err
+}
+
+// SetVersion parses ver as a semantic version (see Parse) and sets it as the
+// application version. Raw is always updated, even when ver is invalid; in that
+// case the numeric fields are left zero and the parse error is returned.
+//
+// git describe output such as v1.2.3-4-gabc1234 is recognized (see
+// ParseDescribe): it is stored in Git().Describe and the version is set to
+// its Describe.SemVer form, 1.2.4-dev.4+gabc1234.
+func (i *Info) SetVersion(ver string) error {
+	var err error
+	i.update(func(s *state) { err = s.setVersion(ver, SourceSetter) })
+	return err
+}
+
+// Get returns the version.
+func (i *Info) Get() Version {
+	return i.load().version
+}
+
+// Build returns the build timestamp and git info.
+func (i *Info) Build() BuildInfo {
+	return i.load().build
+}
+
+// Git returns the git info.
+func (i *Info) Git() GitInfo {
+	return i.load().build.Git
+}
+
+// App returns the application info.
+func (i *Info) App() AppInfo {
+	return i.load().app
+}
+
+// Snapshot returns the application, version and build info from a single
+// snapshot, so the fields are consistent with each other even while another
+// goroutine updates i.
+func (i *Info) Snapshot() Metadata {
+	s := i.load()
+	return Metadata{App: s.app, Version: s.version, Build: s.build}
+}
+
+// ChangesSince returns the changelog releases newer than v.
+func (i *Info) ChangesSince(v Version) []Release {
+	return i.load().app.Changes.ChangesSince(v)
+}
+
+// Provenance returns the source of every field that has a value.
+func (i *Info) Provenance() Sources {
+	return i.load().prov.clone()
+}
+
+// Print writes the application, version and build info to stdout.
+func (i *Info) Print() {
+	i.load().print()
+}
+
+// PrintVerbose writes the same output as Print followed by the source of
+// every field that has a value.
+func (i *Info) PrintVerbose() {
+	s := i.load()
+	s.print()
+	fmt.Println("Sources:")
+	fields := append([]Field(nil), fieldOrder...)
+	for _, key := range sortedKeys(s.build.Extra) {
+		fields = append(fields, ExtraField(key))
+	}
+	for _, f := range fields {
+		if src, ok := s.prov[f]; ok {
+			fmt.Printf("  %-16s %s\n", f, src)
+		}
+	}
+}
+
+// LoadFromFile loads version information from a version file, filling fields
+// that are not set yet. The format is detected from the extension, see
+// FileProvider. Keys of the default key=value format: APP_NAME,
+// APP_DESCRIPTION, VERSION, GIT_COMMIT, GIT_BRANCH, GIT_REPO, GIT_DIRTY,
+// BUILD_TIMESTAMP
+func (i *Info) LoadFromFile(path string) error {
+	return i.loadOne(FileProvider(path))
+}
+
+// LoadFromFS loads version information from the file name in fsys, filling
+// fields that are not set yet. Use it with go:embed to compile the version
+// file into the binary:
+//
+//	//go:embed .version
+//	var versionFS embed.FS
+//
+//	version.LoadFromFS(versionFS, ".version")
+func (i *Info) LoadFromFS(fsys fs.FS, name string) error {
+	return i.loadOne(FSProvider(fsys, name))
+}
+
+// LoadFromBytes loads version information from version file content in the
+// key=value format, filling fields that are not set yet, e.g. from a
+// //go:embed .version []byte variable. Use LoadFromFS to detect the format
+// from the file name.
+func (i *Info) LoadFromBytes(data []byte) error {
+	return i.loadOne(BytesProvider(data, FormatEnv))
+}
+
+// LoadFromGit reads version information from the git repository containing the
+// working directory, filling fields that are not set yet. The repository is
+// read directly, without the git binary, which is only run if installed to
+// detect uncommitted changes, see GitProvider. This is useful during
+// development with 'go run'.
+func (i *Info) LoadFromGit() error {
+	return i.loadOne(GitProvider())
+}
+
+func (s *state) print() {
+	fmt.Println("Running:", s.app)
+	fmt.Println("Version:", s.version)
+	fmt.Println("Build:", s.build)
+}
+
+func (s *state) setAppInfo(name, description string, src Source) {
+	if s.app.Name == "" {
+		s.app.Name = name
+		s.app.Description = description
+		s.prov.record(FieldAppName, src, name)
+		s.prov.record(FieldAppDescription, src, description)
+	}
+}
+
+func (s *state) setGitInfo(commit, branch, repo string, src Source) {
+	if s.build.Git.Commit == "" {
+		s.setCommit(commit, src)
+		s.setBranch(branch, src)
+		s.setRepo(repo, src)
+	}
+}
+
+// setExtra sets an extra label. The map is copied, it may be shared with
+// published snapshots.
+func (s *state) setExtra(key, value string, src Source) {
+	extra := make(map[string]string, len(s.build.Extra)+1)
+	for k, v := range s.build.Extra {
+		extra[k] = v
+	}
+	extra[key] = value
+	s.build.Extra = extra
+	s.prov[ExtraField(key)] = src
+}
+
+func (s *state) setCommit(commit string, src Source) {
+	s.build.Git.Commit = commit
+	s.prov.record(FieldCommit, src, commit)
+}
+
+func (s *state) setBranch(branch string, src Source) {
+	s.build.Git.Branch = branch
+	s.prov.record(FieldBranch, src, branch)
+}
+
+func (s *state) setRepo(repo string, src Source) {
+	s.build.Git.Repo = repo
+	s.prov.record(FieldRepo, src, repo)
+}
+
+// supportedFormats lists time formats that SetBuildInfo will try when parsing timestamps.
+var supportedFormats = []string{
+	time.UnixDate,
+	time.RFC3339,
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC822,
+	time.RFC850,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseTimestamp parses a timestamp in one of the supportedFormats.
+func parseTimestamp(timestamp string) (time.Time, bool) {
+	for _, format := range supportedFormats {
+		if t, err := time.Parse(format, timestamp); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (s *state) setBuildInfo(timestamp string, src Source) {
+	if !s.build.Timestamp.IsZero() {
+		return
+	}
+	if t, ok := parseTimestamp(timestamp); ok {
+		s.build.Timestamp = t
+		s.prov.record(FieldTimestamp, src, timestamp)
+	}
+}
+
+func (s *state) setChangelog(changelog string, src Source) error {
+	if s.app.Changelog != "" {
+		return nil
+	}
+	s.app.Changelog = changelog
+	s.prov.record(FieldChangelog, src, changelog)
+	parsed, err := ParseChangelog(changelog)
+	if err != nil {
+		return err
+	}
+	s.app.Changes = parsed
+	return nil
+}
+
+func (s *state) setVersion(ver string, src Source) error {
+	s.prov.record(FieldVersion, src, ver)
+	s.build.Git.Describe = Describe{}
+	if ver == "" {
+		s.version = Version{}
+		return nil
+	}
+	var v Version
+	var err error
+	if d, ok := ParseDescribe(ver); ok {
+		s.build.Git.Describe = d
+		v, err = d.SemVer()
+	} else {
+		v, err = Parse(ver)
+	}
+	if err != nil {
+		s.version = Version{Raw: ver}
+		return err
+	}
+	v.Raw = ver
+	s.version = v
+	return nil
+}
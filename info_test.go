@@ -0,0 +1,191 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestNew_Empty(t *testing.T) {
+	i := New()
+	if i.Get().Raw != "" || i.Git().Commit != "" || i.App().Name != "" || !i.Build().Timestamp.IsZero() {
+		t.Errorf("New() should be empty, got %+v %+v %+v", i.Get(), i.Build(), i.App())
+	}
+}
+
+func TestNew_Options(t *testing.T) {
+	i := New(
+		WithAppInfo("plugin", "A plugin"),
+		WithVersion("v1.4.0-rc.1"),
+		WithGitInfo("abc123", "main", "github.com/org/plugin"),
+		WithBuildInfo("2024-01-15T10:30:00Z"),
+		WithChangelog("## [1.4.0-rc.1] - 2024-01-15\n- First\n"),
+	)
+
+	if a := i.App(); a.Name != "plugin" || a.Description != "A plugin" {
+		t.Errorf("App() = %+v", a)
+	}
+	if v := i.Get(); v.Raw != "v1.4.0-rc.1" || v.Minor != 4 || v.PrereleaseString() != "rc.1" {
+		t.Errorf("Get() = %+v", v)
+	}
+	if g := i.Git(); g.Commit != "abc123" || g.Branch != "main" || g.Repo != "github.com/org/plugin" {
+		t.Errorf("Git() = %+v", g)
+	}
+	if b := i.Build(); b.Timestamp.Year() != 2024 || b.Git.Commit != "abc123" {
+		t.Errorf("Build() = %+v", b)
+	}
+	if len(i.App().Changes.Releases) != 1 {
+		t.Errorf("changelog should be parsed, got %+v", i.App().Changes)
+	}
+}
+
+func TestNew_OptionsFirstWins(t *testing.T) {
+	i := New(WithAppInfo("first", ""), WithAppInfo("second", ""), WithGitInfo("c1", "", ""), WithGitInfo("c2", "", ""))
+	if i.App().Name != "first" || i.Git().Commit != "c1" {
+		t.Errorf("options should follow setter semantics, got %q %q", i.App().Name, i.Git().Commit)
+	}
+}
+
+func TestNew_Independent(t *testing.T) {
+	resetState()
+	SetAppInfo("host", "Host binary")
+	SetVersion("2.0.0")
+
+	lib := New(WithAppInfo("lib", "Library"), WithVersion("0.3.1"))
+
+	if Get().Raw != "2.0.0" || App().Name != "host" {
+		t.Errorf("default instance changed: %+v %+v", Get(), App())
+	}
+	if lib.Get().Raw != "0.3.1" || lib.App().Name != "lib" {
+		t.Errorf("instance = %+v %+v", lib.Get(), lib.App())
+	}
+
+	lib.SetGitInfo("libcommit", "", "")
+	if Git().Commit != "" {
+		t.Errorf("instance setter leaked into default instance: %q", Git().Commit)
+	}
+}
+
+func TestNew_WithLdflags(t *testing.T) {
+	old := [...]string{VersionInfo, GitCommit, GitBranch, GitRepo, BuildTimestamp}
+	defer func() {
+		VersionInfo, GitCommit, GitBranch, GitRepo, BuildTimestamp = old[0], old[1], old[2], old[3], old[4]
+	}()
+	VersionInfo, GitCommit, GitBranch, GitRepo, BuildTimestamp = "v3.2.1", "ldcommit", "release", "repo", "2024-02-03"
+
+	i := New(WithLdflags())
+	if i.Get().Raw != "v3.2.1" || i.Git().Commit != "ldcommit" || i.Git().Branch != "release" || i.Build().Timestamp.Day() != 3 {
+		t.Errorf("WithLdflags() = %+v %+v", i.Get(), i.Build())
+	}
+}
+
+func TestNew_WithRuntimeBuildInfo(t *testing.T) {
+	// Under 'go test' Main.Version is "(devel)" and must be skipped; this
+	// verifies the option does not panic or overwrite preset values.
+	i := New(WithVersion("1.0.0"), WithRuntimeBuildInfo())
+	if i.Get().Raw != "1.0.0" {
+		t.Errorf("Raw = %q, want 1.0.0", i.Get().Raw)
+	}
+}
+
+func TestInfo_LoadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".version")
+	if err := os.WriteFile(path, []byte("VERSION=1.2.3\nGIT_COMMIT=filecommit\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resetState()
+	i := New()
+	if err := i.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if i.Get().Raw != "1.2.3" || i.Git().Commit != "filecommit" {
+		t.Errorf("LoadFromFile() = %+v %+v", i.Get(), i.Git())
+	}
+	if Get().Raw != "" {
+		t.Errorf("instance load leaked into default instance: %q", Get().Raw)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	resetState()
+	SetVersion("4.5.6")
+	if Default().Get().Raw != "4.5.6" {
+		t.Errorf("Default().Get() = %q, want 4.5.6", Default().Get().Raw)
+	}
+}
+
+func TestInfo_ConcurrentAccess(t *testing.T) {
+	// Run with -race: writers and readers must not conflict.
+	path := filepath.Join(t.TempDir(), ".version")
+	if err := os.WriteFile(path, []byte("VERSION=1.2.3\nGIT_BRANCH=main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	i := New()
+	var wg sync.WaitGroup
+	for n := 0; n < 8; n++ {
+		wg.Add(2)
+		go func(n int) {
+			defer wg.Done()
+			_ = i.SetVersion(fmt.Sprintf("1.0.%d", n))
+			i.SetGitInfo(fmt.Sprintf("commit%d", n), "main", "repo")
+			i.SetBuildInfo("2024-01-02")
+			i.SetAppInfo("app", "desc")
+			_ = i.SetChangelog("## [1.0.0] - 2024-01-01\n- x\n")
+			_ = i.LoadFromFile(path)
+		}(n)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 100; k++ {
+				_ = i.Get().String()
+				_ = i.Build().Git.Commit
+				_ = i.App().Name
+				_ = i.ChangesSince(Version{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	if i.Git().Commit == "" || i.App().Name != "app" || i.Get().Raw == "" {
+		t.Errorf("unexpected final state: %+v %+v %+v", i.Get(), i.Git(), i.App())
+	}
+}
+
+func TestInfo_ZeroValue(t *testing.T) {
+	var i Info
+	if i.Get().Raw != "" {
+		t.Errorf("zero Info should be empty, got %q", i.Get().Raw)
+	}
+	_ = i.SetVersion("1.0.0")
+	if i.Get().Raw != "1.0.0" {
+		t.Errorf("zero Info should be usable, got %q", i.Get().Raw)
+	}
+}
+
+func TestSetExtra(t *testing.T) {
+	i := New()
+	i.SetExtra("channel", "beta")
+	i.SetExtra("channel", "stable")
+	i.SetExtra("pipeline", "1234")
+
+	extra := i.Build().Extra
+	if extra["channel"] != "beta" || extra["pipeline"] != "1234" {
+		t.Errorf("Extra = %v", extra)
+	}
+	if src := i.Provenance()[ExtraField("channel")]; src != SourceSetter {
+		t.Errorf("extra.channel source = %q, want setter", src)
+	}
+}
+
+func TestSetExtra_SnapshotsIndependent(t *testing.T) {
+	i := New()
+	i.SetExtra("a", "1")
+	before := i.Build().Extra
+	i.SetExtra("b", "2")
+	if _, ok := before["b"]; ok {
+		t.Error("setting a label should not modify earlier snapshots")
+	}
+}
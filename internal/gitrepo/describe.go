@@ -0,0 +1,211 @@
+package gitrepo
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxCandidates is the number of tags Describe considers, as git describe.
+const maxCandidates = 10
+
+// maxPeelDepth limits how many nested tag objects are followed.
+const maxPeelDepth = 10
+
+// AbbrevLength is the length of abbreviated commit names in Describe output.
+const AbbrevLength = 7
+
+// TagRef is a tag and the commit it points to.
+type TagRef struct {
+	// name without the refs/tags/ prefix
+	Name string
+	// commit the tag peels to
+	Commit string
+	// whether the tag is an annotated tag object
+	Annotated bool
+	// tagger date of an annotated tag
+	Time time.Time
+}
+
+// Tags returns the tags that point to commits, sorted by name. Annotated
+// tags are peeled to their commit; tags of trees and blobs are skipped.
+func (r *Repo) Tags() ([]TagRef, error) {
+	refs, err := r.refs("refs/tags/")
+	if err != nil {
+		return nil, err
+	}
+	var tags []TagRef
+	for name, hash := range refs {
+		tag := TagRef{Name: strings.TrimPrefix(name, "refs/tags/")}
+		ok, err := r.peel(&tag, hash)
+		if err != nil {
+			return nil, fmt.Errorf("tag %s: %w", tag.Name, err)
+		}
+		if ok {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// peel follows annotated tags from hash to a commit, recording the outermost
+// tag's date. It reports false if the tag does not point to a commit.
+func (r *Repo) peel(tag *TagRef, hash string) (bool, error) {
+	for i := 0; ; i++ {
+		typ, err := r.objectType(hash)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case typ == "commit":
+			tag.Commit = hash
+			return true, nil
+		case typ != "tag" || i >= maxPeelDepth:
+			return false, nil
+		}
+		t, err := r.ReadTag(hash)
+		if err != nil {
+			return false, err
+		}
+		if i == 0 {
+			tag.Annotated = true
+			tag.Time = t.Time
+		}
+		hash = t.Object
+	}
+}
+
+// Describe names commit after the nearest tag reachable from it, like
+// git describe --tags --always: the tag itself if it points to commit,
+// "<tag>-<distance>-g<abbrev>" otherwise, and the abbreviated commit name if
+// no tag is reachable. The distance is the number of commits reachable from
+// commit but not from the tag.
+func (r *Repo) Describe(commit string) (string, error) {
+	tags, err := r.Tags()
+	if err != nil {
+		return "", err
+	}
+	byCommit := map[string]TagRef{}
+	for _, t := range tags {
+		if cur, ok := byCommit[t.Commit]; !ok || betterTag(t, cur) {
+			byCommit[t.Commit] = t
+		}
+	}
+	if t, ok := byCommit[commit]; ok {
+		return t.Name, nil
+	}
+
+	abbrev := commit
+	if len(abbrev) > AbbrevLength {
+		abbrev = abbrev[:AbbrevLength]
+	}
+	best, depth, err := r.nearestTag(commit, byCommit)
+	if err != nil || best == "" {
+		return abbrev, err
+	}
+	return fmt.Sprintf("%s-%d-g%s", best, depth, abbrev), nil
+}
+
+// betterTag reports whether a should name a commit instead of b: annotated
+// tags win over lightweight ones, then the newer tag, then the smaller name.
+func betterTag(a, b TagRef) bool {
+	if a.Annotated != b.Annotated {
+		return a.Annotated
+	}
+	if !a.Time.Equal(b.Time) {
+		return a.Time.After(b.Time)
+	}
+	return a.Name < b.Name
+}
+
+// candidate is a tagged commit found while walking history.
+type candidate struct {
+	tag   string
+	depth int
+}
+
+// nearestTag walks history from commit, newest first, and returns the tag
+// with the fewest commits between it and commit. Like git, it considers the
+// first maxCandidates tagged commits found and counts, for each, the walked
+// commits that the tag does not reach.
+func (r *Repo) nearestTag(commit string, byCommit map[string]TagRef) (string, int, error) {
+	start, err := r.ReadCommit(commit)
+	if err != nil {
+		return "", 0, err
+	}
+	// reach[hash] has bit i set if candidate i reaches the commit
+	reach := map[string]uint{commit: 0}
+	queue := &commitQueue{start}
+	var cands []candidate
+
+	for walked := 0; queue.Len() > 0; walked++ {
+		c := heap.Pop(queue).(*Commit)
+		flags := reach[c.Hash]
+		if t, ok := byCommit[c.Hash]; ok && len(cands) < maxCandidates {
+			// the commits walked so far are not reachable from the tag
+			flags |= 1 << uint(len(cands))
+			cands = append(cands, candidate{tag: t.Name, depth: walked})
+		}
+		for i := range cands {
+			if flags&(1<<uint(i)) == 0 {
+				cands[i].depth++
+			}
+		}
+		all := uint(1)<<uint(len(cands)) - 1
+		if len(cands) == maxCandidates && queue.reachedBy(reach, all) {
+			// everything left is reachable from every candidate
+			break
+		}
+		for _, p := range c.Parents {
+			prev, seen := reach[p]
+			reach[p] = prev | flags
+			if seen {
+				continue
+			}
+			parent, err := r.ReadCommit(p)
+			if err != nil {
+				// shallow clones have parents without objects
+				continue
+			}
+			heap.Push(queue, parent)
+		}
+	}
+
+	if len(cands) == 0 {
+		return "", 0, nil
+	}
+	best := cands[0]
+	for _, c := range cands[1:] {
+		if c.depth < best.depth {
+			best = c
+		}
+	}
+	return best.tag, best.depth, nil
+}
+
+// commitQueue is a priority queue of commits, newest committer date first.
+type commitQueue []*Commit
+
+func (q commitQueue) Len() int            { return len(q) }
+func (q commitQueue) Less(i, j int) bool  { return q[i].Time.After(q[j].Time) }
+func (q commitQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x interface{}) { *q = append(*q, x.(*Commit)) }
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// reachedBy reports whether every queued commit has all the given flags.
+func (q commitQueue) reachedBy(reach map[string]uint, flags uint) bool {
+	for _, c := range q {
+		if reach[c.Hash]&flags != flags {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,127 @@
+package gitrepo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	f := newFixture(t)
+	c1 := f.commit("one")
+	c2 := f.commit("two", c1)
+	f.write("refs/tags/light", c1+"\n")
+	f.annotatedTag("v1.0.0", c2)
+	f.write("refs/tags/tree", f.object("tree", "")+"\n")
+	f.write("packed-refs", c2+" refs/tags/packed\n")
+
+	tags, err := f.open().Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tag := range tags {
+		got = append(got, tag.Name+"="+tag.Commit[:7])
+		if tag.Annotated != (tag.Name == "v1.0.0") {
+			t.Errorf("%s: Annotated = %v", tag.Name, tag.Annotated)
+		}
+	}
+	want := []string{"light=" + c1[:7], "packed=" + c2[:7], "v1.0.0=" + c2[:7]}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Tags() = %v, want %v", got, want)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	f := newFixture(t)
+	c1 := f.commit("one")
+	c2 := f.commit("two", c1)
+	f.write("refs/tags/light", c2+"\n")
+	f.annotatedTag("v1.0.0", c2)
+	c3 := f.commit("three", c2)
+	side := f.commit("side", c3)
+	main := f.commit("main", c3)
+	f.annotatedTag("v1.1.0-rc.1", side)
+	merge := f.commit("merge", main, side)
+	r := f.open()
+
+	tests := []struct {
+		commit, want string
+	}{
+		{c1, c1[:7]},
+		// annotated tags are preferred over lightweight ones
+		{c2, "v1.0.0"},
+		{c3, "v1.0.0-1-g" + c3[:7]},
+		{main, "v1.0.0-2-g" + main[:7]},
+		// the merge reaches side in one step but needs three from v1.0.0
+		{merge, "v1.1.0-rc.1-2-g" + merge[:7]},
+	}
+	for _, tt := range tests {
+		if got, err := r.Describe(tt.commit); got != tt.want || err != nil {
+			t.Errorf("Describe(%s) = %q, %v, want %q", tt.commit[:7], got, err, tt.want)
+		}
+	}
+}
+
+// TestDescribe_GitBinary compares with git on a repository packed by git gc,
+// which stores most commits as deltas.
+func TestDescribe_GitBinary(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("skipping: git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	git("init", "-q")
+	git("symbolic-ref", "HEAD", "refs/heads/main")
+	git("remote", "add", "origin", "https://example.com/org/app.git")
+	for i := 0; i < 30; i++ {
+		content := strings.Repeat("line of a file that changes a little\n", 50) + strings.Repeat("x", i)
+		if err := os.WriteFile(filepath.Join(dir, "file"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", "file")
+		git("commit", "-q", "-m", "commit "+strings.Repeat("word ", i))
+		switch i {
+		case 5:
+			git("tag", "-a", "-m", "release", "v1.0.0")
+		case 12:
+			git("tag", "v1.1.0")
+		}
+	}
+	git("gc", "-q", "--aggressive")
+
+	r, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	branch, head, err := r.Head()
+	if err != nil || branch != "main" || head != git("rev-parse", "HEAD") {
+		t.Errorf("Head() = %q, %q, %v", branch, head, err)
+	}
+	if url := r.RemoteURL("origin"); url != git("remote", "get-url", "origin") {
+		t.Errorf("RemoteURL() = %q", url)
+	}
+	for _, rev := range []string{"HEAD", "HEAD~3", "HEAD~17", "HEAD~24", "HEAD~29"} {
+		commit := git("rev-parse", rev)
+		want := git("describe", "--tags", "--always", "--abbrev=7", commit)
+		if got, err := r.Describe(commit); got != want || err != nil {
+			t.Errorf("Describe(%s) = %q, %v, want %q", rev, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,472 @@
+package gitrepo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Object types as stored in pack files.
+const (
+	objCommit   = 1
+	objTree     = 2
+	objBlob     = 3
+	objTag      = 4
+	objOfsDelta = 6
+	objRefDelta = 7
+)
+
+var typeNames = map[int]string{objCommit: "commit", objTree: "tree", objBlob: "blob", objTag: "tag"}
+
+// typeCode returns the pack type of an object type name.
+func typeCode(name string) int {
+	for code, n := range typeNames {
+		if n == name {
+			return code
+		}
+	}
+	return 0
+}
+
+// maxObjectSize bounds the size read for a single object, commits and tags
+// are far smaller.
+const maxObjectSize = 64 << 20
+
+// Commit is a parsed commit object.
+type Commit struct {
+	Hash    string
+	Parents []string
+	// committer date
+	Time time.Time
+}
+
+// Tag is a parsed annotated tag object.
+type Tag struct {
+	Hash string
+	Name string
+	// object the tag points to and its type
+	Object string
+	Type   string
+	// tagger date, zero if the tag has no tagger
+	Time time.Time
+}
+
+// objectStore reads objects from a repository's objects directory and its
+// alternates.
+type objectStore struct {
+	dirs  []string
+	packs []*pack
+}
+
+func openObjectStore(dir string) (*objectStore, error) {
+	s := &objectStore{dirs: []string{dir}}
+	if data, err := os.ReadFile(filepath.Join(dir, "info", "alternates")); err == nil { // #nosec G304 -- inside the git directory
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			if !filepath.IsAbs(line) {
+				line = filepath.Join(dir, line)
+			}
+			s.dirs = append(s.dirs, line)
+		}
+	}
+	for _, d := range s.dirs {
+		idxs, _ := filepath.Glob(filepath.Join(d, "pack", "pack-*.idx"))
+		for _, idx := range idxs {
+			p, err := openPack(idx)
+			if err != nil {
+				_ = s.close()
+				return nil, err
+			}
+			s.packs = append(s.packs, p)
+		}
+	}
+	return s, nil
+}
+
+func (s *objectStore) close() error {
+	var first error
+	for _, p := range s.packs {
+		if err := p.file.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	s.packs = nil
+	return first
+}
+
+// read returns the type and content of an object.
+func (s *objectStore) read(hash string) (string, []byte, error) {
+	if !isHash(hash) {
+		return "", nil, fmt.Errorf("invalid object name %q", hash)
+	}
+	for _, d := range s.dirs {
+		typ, data, err := readLoose(filepath.Join(d, hash[:2], hash[2:]))
+		if err == nil {
+			return typ, data, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("object %s: %w", hash, err)
+		}
+	}
+	raw, _ := hex.DecodeString(hash)
+	for _, p := range s.packs {
+		if off, ok := p.find(raw); ok {
+			typ, data, err := p.readAt(s, off)
+			if err != nil {
+				return "", nil, fmt.Errorf("object %s: %w", hash, err)
+			}
+			return typeNames[typ], data, nil
+		}
+	}
+	return "", nil, fmt.Errorf("object %s: %w", hash, ErrNotFound)
+}
+
+// readLoose reads a zlib-compressed "<type> <size>\x00<content>" object.
+func readLoose(path string) (string, []byte, error) {
+	f, err := os.Open(path) // #nosec G304 -- object inside the git directory
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+	zr, err := zlib.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	br := bufio.NewReader(zr)
+	header, err := br.ReadString(0)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid loose object header: %w", err)
+	}
+	typ, sizeStr, ok := strings.Cut(strings.TrimSuffix(header, "\x00"), " ")
+	size, err := strconv.Atoi(sizeStr)
+	if !ok || err != nil || size < 0 || size > maxObjectSize {
+		return "", nil, fmt.Errorf("invalid loose object header %q", header)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return "", nil, err
+	}
+	return typ, data, nil
+}
+
+// pack is a pack file with its version 2 index.
+type pack struct {
+	file *os.File
+	// fanout[b] is the number of objects whose first byte is <= b
+	fanout  [256]uint32
+	names   []byte
+	offsets []byte
+	large   []byte
+}
+
+var idxMagic = []byte{0xff, 't', 'O', 'c'}
+
+func openPack(idxPath string) (*pack, error) {
+	idx, err := os.ReadFile(idxPath) // #nosec G304 -- pack index inside the git directory
+	if err != nil {
+		return nil, err
+	}
+	const headerLen = 8 + 256*4
+	if len(idx) < headerLen || !bytes.Equal(idx[:4], idxMagic) || binary.BigEndian.Uint32(idx[4:8]) != 2 {
+		return nil, fmt.Errorf("%w: pack index %s is not version 2", ErrUnsupported, idxPath)
+	}
+	p := &pack{}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(idx[8+i*4:])
+	}
+	n := int(p.fanout[255])
+	namesEnd := headerLen + n*20
+	offsetsStart := namesEnd + n*4 // skip CRC32s
+	offsetsEnd := offsetsStart + n*4
+	if len(idx) < offsetsEnd+40 {
+		return nil, fmt.Errorf("pack index %s is truncated", idxPath)
+	}
+	p.names = idx[headerLen:namesEnd]
+	p.offsets = idx[offsetsStart:offsetsEnd]
+	p.large = idx[offsetsEnd : len(idx)-40]
+
+	p.file, err = os.Open(strings.TrimSuffix(idxPath, ".idx") + ".pack")
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// find returns the pack offset of the object with the raw 20-byte name.
+func (p *pack) find(raw []byte) (int64, bool) {
+	lo := 0
+	if raw[0] > 0 {
+		lo = int(p.fanout[raw[0]-1])
+	}
+	hi := int(p.fanout[raw[0]])
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(p.names[(lo+i)*20:(lo+i+1)*20], raw) >= 0
+	})
+	if i >= hi || !bytes.Equal(p.names[i*20:(i+1)*20], raw) {
+		return 0, false
+	}
+	off := binary.BigEndian.Uint32(p.offsets[i*4:])
+	if off&0x80000000 == 0 {
+		return int64(off), true
+	}
+	// offsets beyond 2 GiB are stored in the large offset table
+	j := int(off & 0x7fffffff)
+	if (j+1)*8 > len(p.large) {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(p.large[j*8:])), true
+}
+
+// readAt reads the object at off, applying deltas. Bases stored by name
+// (ref deltas) are looked up in s.
+func (p *pack) readAt(s *objectStore, off int64) (int, []byte, error) {
+	br := bufio.NewReader(io.NewSectionReader(p.file, off, 1<<62))
+	c, err := br.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	typ := int(c>>4) & 7
+	size := int64(c & 0x0f)
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		size |= int64(c&0x7f) << shift
+	}
+	if size > maxObjectSize {
+		return 0, nil, fmt.Errorf("object of %d bytes is too large", size)
+	}
+
+	switch typ {
+	case objCommit, objTree, objBlob, objTag:
+		data, err := inflate(br, size)
+		return typ, data, err
+
+	case objOfsDelta:
+		// base offset relative to this entry, big-endian base-128 with an
+		// offset of one added to every continuation byte
+		if c, err = br.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			rel = (rel+1)<<7 | int64(c&0x7f)
+		}
+		if rel <= 0 || rel > off {
+			return 0, nil, errors.New("invalid delta base offset")
+		}
+		delta, err := inflate(br, size)
+		if err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err := p.readAt(s, off-rel)
+		if err != nil {
+			return 0, nil, err
+		}
+		data, err := applyDelta(base, delta)
+		return baseType, data, err
+
+	case objRefDelta:
+		raw := make([]byte, 20)
+		if _, err := io.ReadFull(br, raw); err != nil {
+			return 0, nil, err
+		}
+		delta, err := inflate(br, size)
+		if err != nil {
+			return 0, nil, err
+		}
+		baseType, base, err := s.read(hex.EncodeToString(raw))
+		if err != nil {
+			return 0, nil, err
+		}
+		data, err := applyDelta(base, delta)
+		return typeCode(baseType), data, err
+	}
+	return 0, nil, fmt.Errorf("invalid pack object type %d", typ)
+}
+
+// inflate decompresses a zlib stream of the given decompressed size.
+func inflate(r io.Reader, size int64) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(zr, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// applyDelta rebuilds an object from its base and a git delta: the base and
+// result sizes followed by copy (from base) and insert (literal) instructions.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	errInvalid := errors.New("invalid delta")
+	varint := func() (int, bool) {
+		n, shift := 0, 0
+		for len(delta) > 0 {
+			c := delta[0]
+			delta = delta[1:]
+			n |= int(c&0x7f) << shift
+			if c&0x80 == 0 {
+				return n, true
+			}
+			shift += 7
+		}
+		return 0, false
+	}
+	baseSize, ok1 := varint()
+	size, ok2 := varint()
+	if !ok1 || !ok2 || baseSize != len(base) || size > maxObjectSize {
+		return nil, errInvalid
+	}
+
+	out := make([]byte, 0, size)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+		if op&0x80 == 0 {
+			// insert op bytes
+			n := int(op)
+			if n == 0 || n > len(delta) {
+				return nil, errInvalid
+			}
+			out = append(out, delta[:n]...)
+			delta = delta[n:]
+			continue
+		}
+		// copy: bits 0-3 select offset bytes, bits 4-6 size bytes
+		var offset, n int
+		for i := 0; i < 7; i++ {
+			if op&(1<<i) == 0 {
+				continue
+			}
+			if len(delta) == 0 {
+				return nil, errInvalid
+			}
+			if i < 4 {
+				offset |= int(delta[0]) << (8 * i)
+			} else {
+				n |= int(delta[0]) << (8 * (i - 4))
+			}
+			delta = delta[1:]
+		}
+		if n == 0 {
+			n = 0x10000
+		}
+		if offset+n > len(base) {
+			return nil, errInvalid
+		}
+		out = append(out, base[offset:offset+n]...)
+	}
+	if len(out) != size {
+		return nil, errInvalid
+	}
+	return out, nil
+}
+
+// ReadCommit reads and parses a commit object.
+func (r *Repo) ReadCommit(hash string) (*Commit, error) {
+	typ, data, err := r.objects.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	if typ != "commit" {
+		return nil, fmt.Errorf("object %s is a %s, not a commit", hash, typ)
+	}
+	c := &Commit{Hash: hash}
+	for _, line := range headerLines(data) {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "parent":
+			c.Parents = append(c.Parents, value)
+		case "committer":
+			c.Time = signatureTime(value)
+		}
+	}
+	return c, nil
+}
+
+// ReadTag reads and parses an annotated tag object.
+func (r *Repo) ReadTag(hash string) (*Tag, error) {
+	typ, data, err := r.objects.read(hash)
+	if err != nil {
+		return nil, err
+	}
+	if typ != "tag" {
+		return nil, fmt.Errorf("object %s is a %s, not a tag", hash, typ)
+	}
+	t := &Tag{Hash: hash}
+	for _, line := range headerLines(data) {
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "object":
+			t.Object = value
+		case "type":
+			t.Type = value
+		case "tag":
+			t.Name = value
+		case "tagger":
+			t.Time = signatureTime(value)
+		}
+	}
+	return t, nil
+}
+
+// objectType returns the type of an object.
+func (r *Repo) objectType(hash string) (string, error) {
+	typ, _, err := r.objects.read(hash)
+	return typ, err
+}
+
+// headerLines returns the header lines of a commit or tag, up to the blank
+// line before the message.
+func headerLines(data []byte) []string {
+	header, _, _ := strings.Cut(string(data), "\n\n")
+	return strings.Split(header, "\n")
+}
+
+// signatureTime parses the time of "Name <email> 1700000000 +0100".
+func signatureTime(sig string) time.Time {
+	fields := strings.Fields(sig[strings.LastIndex(sig, ">")+1:])
+	if len(fields) == 0 {
+		return time.Time{}
+	}
+	sec, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	t := time.Unix(sec, 0)
+	if len(fields) > 1 && len(fields[1]) == 5 {
+		tz := fields[1]
+		h, err1 := strconv.Atoi(tz[1:3])
+		m, err2 := strconv.Atoi(tz[3:5])
+		if err1 == nil && err2 == nil {
+			offset := (h*60 + m) * 60
+			if tz[0] == '-' {
+				offset = -offset
+			}
+			t = t.In(time.FixedZone(tz, offset))
+		}
+	}
+	return t
+}
@@ -0,0 +1,239 @@
+package gitrepo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1" // #nosec G505 -- git pack checksums
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// packEntry is an object to store in a test pack: a full object, or a delta
+// against an earlier entry by offset (ofsBase) or by name (refBase).
+type packEntry struct {
+	typ     int
+	data    []byte
+	ofsBase int
+	refBase string
+	// object name, for delta entries the name of the result
+	name string
+}
+
+// writePack writes a pack and its version 2 index to the objects directory.
+func writePack(t *testing.T, objects string, entries []packEntry) {
+	t.Helper()
+	var pack bytes.Buffer
+	pack.WriteString("PACK")
+	_ = binary.Write(&pack, binary.BigEndian, uint32(2))
+	_ = binary.Write(&pack, binary.BigEndian, uint32(len(entries)))
+
+	offsets := make([]int, len(entries))
+	for i, e := range entries {
+		offsets[i] = pack.Len()
+		size := len(e.data)
+		c := byte(e.typ<<4) | byte(size&0x0f)
+		size >>= 4
+		for size > 0 {
+			pack.WriteByte(c | 0x80)
+			c = byte(size & 0x7f)
+			size >>= 7
+		}
+		pack.WriteByte(c)
+		switch e.typ {
+		case objOfsDelta:
+			rel := offsets[i] - offsets[e.ofsBase]
+			buf := []byte{byte(rel & 0x7f)}
+			for rel >>= 7; rel > 0; rel >>= 7 {
+				rel--
+				buf = append([]byte{byte(0x80 | rel&0x7f)}, buf...)
+			}
+			pack.Write(buf)
+		case objRefDelta:
+			raw, _ := hex.DecodeString(e.refBase)
+			pack.Write(raw)
+		}
+		zw := zlib.NewWriter(&pack)
+		_, _ = zw.Write(e.data)
+		_ = zw.Close()
+	}
+	sum := sha1.Sum(pack.Bytes()) // #nosec G401 -- git pack checksums
+	pack.Write(sum[:])
+
+	order := make([]int, len(entries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return entries[order[a]].name < entries[order[b]].name })
+	var idx bytes.Buffer
+	idx.Write(idxMagic)
+	_ = binary.Write(&idx, binary.BigEndian, uint32(2))
+	var fanout [256]uint32
+	for _, e := range entries {
+		raw, _ := hex.DecodeString(e.name)
+		for b := int(raw[0]); b < 256; b++ {
+			fanout[b]++
+		}
+	}
+	_ = binary.Write(&idx, binary.BigEndian, fanout)
+	for _, i := range order {
+		raw, _ := hex.DecodeString(entries[i].name)
+		idx.Write(raw)
+	}
+	idx.Write(make([]byte, 4*len(entries))) // CRC32s are not checked
+	for _, i := range order {
+		_ = binary.Write(&idx, binary.BigEndian, uint32(offsets[i]))
+	}
+	idx.Write(sum[:])
+	idx.Write(make([]byte, 20))
+
+	dir := filepath.Join(objects, "pack")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Join(dir, "pack-"+hex.EncodeToString(sum[:]))
+	if err := os.WriteFile(name+".pack", pack.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name+".idx", idx.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// makeDelta encodes target as a copy of the first n bytes of base followed
+// by the rest of target.
+func makeDelta(base, target []byte, n int) []byte {
+	varint := func(v int) []byte {
+		var out []byte
+		for v >= 0x80 {
+			out = append(out, byte(v&0x7f|0x80))
+			v >>= 7
+		}
+		return append(out, byte(v))
+	}
+	delta := append(varint(len(base)), varint(len(target))...)
+	// copy from offset 0, two size bytes
+	delta = append(delta, 0x80|0x10|0x20, byte(n), byte(n>>8))
+	for rest := target[n:]; len(rest) > 0; {
+		chunk := rest
+		if len(chunk) > 127 {
+			chunk = chunk[:127]
+		}
+		delta = append(delta, byte(len(chunk)))
+		delta = append(delta, chunk...)
+		rest = rest[len(chunk):]
+	}
+	return delta
+}
+
+// rawObjectName returns the name of an object without writing it.
+func rawObjectName(typ string, data []byte) string {
+	return objectName(append([]byte(fmt.Sprintf("%s %d\x00", typ, len(data))), data...))
+}
+
+func TestReadObject_Pack(t *testing.T) {
+	f := newFixture(t)
+	tree := f.object("tree", "")
+	base := []byte("tree " + tree + "\nauthor A <a@example.com> 1700000000 +0000\ncommitter A <a@example.com> 1700000000 +0000\n\nfirst\n")
+	ofsTarget := append(append([]byte(nil), base[:len(base)-6]...), []byte("second commit with a longer message\n")...)
+	refTarget := append(append([]byte(nil), base[:len(base)-6]...), []byte("third\n")...)
+	tagData := []byte("object " + rawObjectName("commit", base) + "\ntype commit\ntag v1\ntagger A <a@example.com> 1700000100 -0130\n\nv1\n")
+
+	entries := []packEntry{
+		{typ: objCommit, data: base, name: rawObjectName("commit", base)},
+		{typ: objOfsDelta, data: makeDelta(base, ofsTarget, len(base)-6), ofsBase: 0, name: rawObjectName("commit", ofsTarget)},
+		{typ: objRefDelta, data: makeDelta(base, refTarget, len(base)-6), refBase: rawObjectName("commit", base), name: rawObjectName("commit", refTarget)},
+		{typ: objTag, data: tagData, name: rawObjectName("tag", tagData)},
+	}
+	writePack(t, filepath.Join(f.git, "objects"), entries)
+	r := f.open()
+
+	for i, want := range [][]byte{base, ofsTarget, refTarget} {
+		typ, data, err := r.objects.read(entries[i].name)
+		if err != nil || typ != "commit" || !bytes.Equal(data, want) {
+			t.Errorf("entry %d: read() = %q, %q, %v, want %q", i, typ, data, err, want)
+		}
+	}
+
+	tag, err := r.ReadTag(entries[3].name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag.Name != "v1" || tag.Object != entries[0].name || tag.Type != "commit" {
+		t.Errorf("ReadTag() = %+v", tag)
+	}
+	if want := time.Unix(1700000100, 0); !tag.Time.Equal(want) {
+		t.Errorf("tag Time = %v, want %v", tag.Time, want)
+	}
+	if _, offset := tag.Time.Zone(); offset != -(90 * 60) {
+		t.Errorf("tag zone offset = %d, want -0130", offset)
+	}
+
+	if _, _, err := r.objects.read(objectName([]byte("missing"))); !errors.Is(err, ErrNotFound) {
+		t.Errorf("read(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestReadObject_Alternates(t *testing.T) {
+	shared := newFixture(t)
+	c := shared.commit("shared")
+
+	f := newFixture(t)
+	f.write("objects/info/alternates", filepath.Join(shared.git, "objects")+"\n")
+	if _, err := f.open().ReadCommit(c); err != nil {
+		t.Errorf("ReadCommit() from alternate error = %v", err)
+	}
+}
+
+func TestReadCommit(t *testing.T) {
+	f := newFixture(t)
+	p1 := f.commit("one")
+	p2 := f.commit("two")
+	c := f.commit("merge", p1, p2)
+	r := f.open()
+
+	got, err := r.ReadCommit(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Parents) != 2 || got.Parents[0] != p1 || got.Parents[1] != p2 {
+		t.Errorf("Parents = %v, want [%s %s]", got.Parents, p1, p2)
+	}
+	if want := time.Unix(f.tick, 0); !got.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", got.Time, want)
+	}
+	if _, offset := got.Time.Zone(); offset != 2*3600 {
+		t.Errorf("zone offset = %d, want +0200", offset)
+	}
+
+	if _, err := r.ReadCommit(f.object("blob", "x")); err == nil {
+		t.Error("ReadCommit() of a blob should fail")
+	}
+}
+
+func TestApplyDelta_Invalid(t *testing.T) {
+	base := []byte("hello")
+	for _, delta := range [][]byte{
+		{},
+		{4, 5, 0x91, 0, 5},    // wrong base size
+		{5, 5, 0x91, 2, 5},    // copy past the end of base
+		{5, 6, 0x91, 0, 5},    // result shorter than declared
+		{5, 5, 0x91, 0, 3, 4}, // insert past the end of delta
+		{5, 5, 0x91, 0, 5, 0}, // reserved zero opcode
+		{5, 5, 0x91},          // truncated copy
+	} {
+		if _, err := applyDelta(base, delta); err == nil {
+			t.Errorf("applyDelta(%v) should fail", delta)
+		}
+	}
+	got, err := applyDelta(base, []byte{5, 8, 0x91, 0, 5, 3, '!', '!', '!'})
+	if err != nil || string(got) != "hello!!!" {
+		t.Errorf("applyDelta() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,377 @@
+// Package gitrepo reads the metadata go-version needs from a git repository
+// without running the git binary: HEAD, branches and tags from loose refs and
+// packed-refs, remote URLs from the config, and commit and tag objects from
+// loose objects and pack files.
+//
+// It supports SHA-1 repositories with the files ref backend, including linked
+// worktrees and submodules whose .git is a "gitdir:" file. Repositories using
+// other formats return ErrUnsupported so callers can fall back to git.
+package gitrepo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrNotRepository is returned by Open when no repository is found.
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrUnsupported is returned for repository formats the reader does not
+	// implement, e.g. SHA-256 object names or the reftable ref backend.
+	ErrUnsupported = errors.New("unsupported git repository format")
+	// ErrNotFound is returned for missing refs and objects.
+	ErrNotFound = errors.New("not found")
+)
+
+// maxSymrefDepth limits how many symbolic refs are followed, as git does.
+const maxSymrefDepth = 5
+
+// Repo is an open repository. It keeps pack files open; call Close when done.
+// A Repo is not safe for concurrent use.
+type Repo struct {
+	// per-worktree directory holding HEAD
+	gitDir string
+	// shared directory holding objects, refs and config
+	commonDir string
+	config    map[string]string
+	objects   *objectStore
+}
+
+// Open finds the repository containing dir, looking for a .git directory or
+// file in dir and its parents.
+func Open(dir string) (*Repo, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		gitDir, err := findGitDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		if gitDir != "" {
+			return openGitDir(gitDir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, ErrNotRepository
+		}
+		dir = parent
+	}
+}
+
+// findGitDir returns the git directory of the working tree at dir, or "" if
+// dir has no .git entry.
+func findGitDir(dir string) (string, error) {
+	path := filepath.Join(dir, ".git")
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return "", nil
+	case err != nil:
+		return "", err
+	case info.IsDir():
+		return path, nil
+	}
+	// linked worktrees and submodules: "gitdir: <path>"
+	data, err := os.ReadFile(path) // #nosec G304 -- .git file of the repository being read
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", fmt.Errorf("%w: invalid .git file %s", ErrNotRepository, path)
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+	return gitDir, nil
+}
+
+func openGitDir(gitDir string) (*Repo, error) {
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return nil, fmt.Errorf("%w: %s has no HEAD", ErrNotRepository, gitDir)
+	}
+	r := &Repo{gitDir: gitDir, commonDir: gitDir}
+	// linked worktrees share objects, refs and config with the main repository
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil { // #nosec G304 -- inside the git directory
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		r.commonDir = filepath.Clean(common)
+	}
+
+	config, err := readConfig(filepath.Join(r.commonDir, "config"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	r.config = config
+	if f := r.config["extensions.objectformat"]; f != "" && !strings.EqualFold(f, "sha1") {
+		return nil, fmt.Errorf("%w: object format %s", ErrUnsupported, f)
+	}
+	if b := r.config["extensions.refstorage"]; b != "" && !strings.EqualFold(b, "files") {
+		return nil, fmt.Errorf("%w: ref storage %s", ErrUnsupported, b)
+	}
+
+	r.objects, err = openObjectStore(filepath.Join(r.commonDir, "objects"))
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close closes the pack files.
+func (r *Repo) Close() error {
+	return r.objects.close()
+}
+
+// GitDir returns the git directory, e.g. /src/app/.git.
+func (r *Repo) GitDir() string {
+	return r.gitDir
+}
+
+// Head returns the branch checked out, without the refs/heads/ prefix, and
+// the commit of HEAD. The branch is "" for a detached HEAD, the commit is ""
+// on a branch without commits.
+func (r *Repo) Head() (branch, commit string, err error) {
+	target, err := r.readRef("HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	if name := strings.TrimPrefix(target, "ref: "); name != target {
+		branch = strings.TrimPrefix(name, "refs/heads/")
+		commit, err = r.ResolveRef(name)
+		if errors.Is(err, ErrNotFound) {
+			return branch, "", nil
+		}
+		return branch, commit, err
+	}
+	return "", target, nil
+}
+
+// ResolveRef returns the object name a ref points to, following symbolic
+// refs. name is a full ref name such as HEAD or refs/tags/v1.0.0.
+func (r *Repo) ResolveRef(name string) (string, error) {
+	for i := 0; i < maxSymrefDepth; i++ {
+		target, err := r.readRef(name)
+		if err != nil {
+			return "", err
+		}
+		next := strings.TrimPrefix(target, "ref: ")
+		if next == target {
+			return target, nil
+		}
+		name = next
+	}
+	return "", fmt.Errorf("ref %s: too many levels of symbolic refs", name)
+}
+
+// readRef returns the content of a loose ref, or its packed-refs entry.
+func (r *Repo) readRef(name string) (string, error) {
+	// per-worktree refs (HEAD, refs/bisect, ...) live in gitDir
+	for _, dir := range []string{r.gitDir, r.commonDir} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))) // #nosec G304 -- ref inside the git directory
+		if err == nil {
+			if target := strings.TrimSpace(string(data)); target != "" {
+				return target, nil
+			}
+		}
+		if dir == r.commonDir {
+			break
+		}
+	}
+	packed, err := r.packedRefs()
+	if err != nil {
+		return "", err
+	}
+	if ref, ok := packed[name]; ok {
+		return ref.hash, nil
+	}
+	return "", fmt.Errorf("ref %s: %w", name, ErrNotFound)
+}
+
+// packedRef is a packed-refs entry with the commit an annotated tag peels to.
+type packedRef struct {
+	hash, peeled string
+}
+
+// packedRefs parses the packed-refs file.
+func (r *Repo) packedRefs() (map[string]packedRef, error) {
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	refs := map[string]packedRef{}
+	var last string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "" || line[0] == '#':
+		case line[0] == '^':
+			if ref, ok := refs[last]; ok {
+				ref.peeled = line[1:]
+				refs[last] = ref
+			}
+		default:
+			hash, name, ok := strings.Cut(line, " ")
+			if ok {
+				refs[name] = packedRef{hash: hash}
+				last = name
+			}
+		}
+	}
+	return refs, sc.Err()
+}
+
+// refs returns the refs under prefix (e.g. "refs/tags/") with their object
+// names, loose refs taking precedence over packed-refs.
+func (r *Repo) refs(prefix string) (map[string]string, error) {
+	packed, err := r.packedRefs()
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	for name, ref := range packed {
+		if strings.HasPrefix(name, prefix) {
+			out[name] = ref.hash
+		}
+	}
+	root := filepath.Join(r.commonDir, filepath.FromSlash(prefix))
+	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(r.commonDir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path) // #nosec G304 -- ref inside the git directory
+		if err != nil {
+			return err
+		}
+		if hash := strings.TrimSpace(string(data)); isHash(hash) {
+			out[filepath.ToSlash(rel)] = hash
+		}
+		return nil
+	})
+	return out, err
+}
+
+// RemoteURL returns the URL of the named remote from the repository config,
+// or "" if it is not configured.
+func (r *Repo) RemoteURL(name string) string {
+	return r.config["remote."+name+".url"]
+}
+
+// readConfig parses the subset of the git config syntax needed here into a
+// map keyed by "section.subsection.key". Section and key names are lower
+// case, subsection names keep their case. Later values win.
+func readConfig(path string) (map[string]string, error) {
+	f, err := os.Open(path) // #nosec G304 -- config of the repository being read
+	if err != nil {
+		return map[string]string{}, err
+	}
+	defer f.Close()
+
+	config := map[string]string{}
+	var section string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.LastIndex(line, "]")
+			if end < 0 {
+				continue
+			}
+			section = configSection(line[1:end])
+			line = strings.TrimSpace(line[end+1:])
+			if line == "" {
+				continue
+			}
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			// a key without a value is boolean true
+			key, value = line, "true"
+		}
+		config[section+"."+strings.ToLower(strings.TrimSpace(key))] = configValue(value)
+	}
+	return config, sc.Err()
+}
+
+// configSection normalizes `remote "origin"` and the legacy `remote.origin`
+// to remote.origin.
+func configSection(header string) string {
+	name, sub, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok {
+		return strings.ToLower(name)
+	}
+	sub = strings.TrimSpace(sub)
+	sub = strings.TrimSuffix(strings.TrimPrefix(sub, `"`), `"`)
+	sub = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(sub)
+	return strings.ToLower(name) + "." + sub
+}
+
+// configValue strips comments and quotes and resolves escapes.
+func configValue(raw string) string {
+	var sb strings.Builder
+	quoted := false
+	raw = strings.TrimSpace(raw)
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(raw[i])
+			}
+		case (c == '#' || c == ';') && !quoted:
+			return strings.TrimSpace(sb.String())
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// isHash reports whether s is a full hex SHA-1 object name.
+func isHash(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,267 @@
+package gitrepo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1" // #nosec G505 -- git object names
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fixture is a repository built without the git binary.
+type fixture struct {
+	t    *testing.T
+	dir  string
+	git  string
+	tick int64
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	dir := t.TempDir()
+	f := &fixture{t: t, dir: dir, git: filepath.Join(dir, ".git"), tick: 1700000000}
+	for _, d := range []string{"objects", "refs/heads", "refs/tags"} {
+		if err := os.MkdirAll(filepath.Join(f.git, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.write("HEAD", "ref: refs/heads/main\n")
+	f.write("config", "[core]\n\tbare = false\n")
+	return f
+}
+
+// write writes a file relative to the git directory.
+func (f *fixture) write(name, content string) {
+	f.t.Helper()
+	path := filepath.Join(f.git, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		f.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+// object writes a loose object and returns its name.
+func (f *fixture) object(typ, content string) string {
+	f.t.Helper()
+	raw := []byte(fmt.Sprintf("%s %d\x00%s", typ, len(content), content))
+	hash := objectName(raw)
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write(raw)
+	_ = zw.Close()
+	f.write("objects/"+hash[:2]+"/"+hash[2:], buf.String())
+	return hash
+}
+
+func objectName(raw []byte) string {
+	sum := sha1.Sum(raw) // #nosec G401 -- git object names
+	return hex.EncodeToString(sum[:])
+}
+
+// commit writes a commit one second after the previous one.
+func (f *fixture) commit(msg string, parents ...string) string {
+	f.t.Helper()
+	f.tick++
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "tree %s\n", f.object("tree", ""))
+	for _, p := range parents {
+		fmt.Fprintf(&buf, "parent %s\n", p)
+	}
+	fmt.Fprintf(&buf, "author Test <test@example.com> %d +0000\n", f.tick)
+	fmt.Fprintf(&buf, "committer Test <test@example.com> %d +0200\n\n%s\n", f.tick, msg)
+	return f.object("commit", buf.String())
+}
+
+// annotatedTag writes a tag object for target and its ref.
+func (f *fixture) annotatedTag(name, target string) string {
+	f.t.Helper()
+	f.tick++
+	hash := f.object("tag", fmt.Sprintf("object %s\ntype commit\ntag %s\ntagger Test <test@example.com> %d +0000\n\nrelease\n", target, name, f.tick))
+	f.write("refs/tags/"+name, hash+"\n")
+	return hash
+}
+
+func (f *fixture) open() *Repo {
+	f.t.Helper()
+	r, err := Open(f.dir)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.t.Cleanup(func() { _ = r.Close() })
+	return r
+}
+
+func TestOpen_FindsParent(t *testing.T) {
+	f := newFixture(t)
+	sub := filepath.Join(f.dir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if r.GitDir() != f.git {
+		t.Errorf("GitDir() = %q, want %q", r.GitDir(), f.git)
+	}
+}
+
+func TestOpen_NotRepository(t *testing.T) {
+	if _, err := Open(t.TempDir()); !errors.Is(err, ErrNotRepository) {
+		// a parent of the temp directory may be a repository
+		if err == nil {
+			t.Skip("temp directory is inside a git repository")
+		}
+		t.Errorf("Open() error = %v, want ErrNotRepository", err)
+	}
+}
+
+func TestOpen_Unsupported(t *testing.T) {
+	for _, config := range []string{
+		"[extensions]\n\tobjectFormat = sha256\n",
+		"[extensions]\n\trefStorage = reftable\n",
+	} {
+		f := newFixture(t)
+		f.write("config", config)
+		if _, err := Open(f.dir); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Open() with %q error = %v, want ErrUnsupported", config, err)
+		}
+	}
+}
+
+func TestHead(t *testing.T) {
+	f := newFixture(t)
+	r := f.open()
+	if branch, commit, err := r.Head(); branch != "main" || commit != "" || err != nil {
+		t.Errorf("unborn Head() = %q, %q, %v", branch, commit, err)
+	}
+
+	c := f.commit("initial")
+	f.write("refs/heads/main", c+"\n")
+	if branch, commit, err := r.Head(); branch != "main" || commit != c || err != nil {
+		t.Errorf("Head() = %q, %q, %v, want main, %s", branch, commit, err, c)
+	}
+
+	f.write("HEAD", c+"\n")
+	if branch, commit, err := r.Head(); branch != "" || commit != c || err != nil {
+		t.Errorf("detached Head() = %q, %q, %v", branch, commit, err)
+	}
+}
+
+func TestResolveRef_PackedRefs(t *testing.T) {
+	f := newFixture(t)
+	c1 := f.commit("one")
+	c2 := f.commit("two", c1)
+	tag := f.object("tag", fmt.Sprintf("object %s\ntype commit\ntag v1\n\nx\n", c1))
+	f.write("packed-refs", "# pack-refs with: peeled fully-peeled sorted\n"+
+		c1+" refs/heads/main\n"+
+		tag+" refs/tags/v1\n^"+c1+"\n")
+	// loose refs take precedence over packed ones
+	f.write("refs/heads/main", c2+"\n")
+	r := f.open()
+
+	if got, err := r.ResolveRef("HEAD"); got != c2 || err != nil {
+		t.Errorf("ResolveRef(HEAD) = %q, %v, want %s", got, err, c2)
+	}
+	if got, err := r.ResolveRef("refs/tags/v1"); got != tag || err != nil {
+		t.Errorf("ResolveRef(refs/tags/v1) = %q, %v, want %s", got, err, tag)
+	}
+	if _, err := r.ResolveRef("refs/heads/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ResolveRef(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestOpen_Worktree(t *testing.T) {
+	f := newFixture(t)
+	main := f.commit("main")
+	feature := f.commit("feature", main)
+	f.write("refs/heads/main", main+"\n")
+	f.write("refs/heads/feature", feature+"\n")
+	f.write("config", "[remote \"origin\"]\n\turl = https://example.com/repo.git\n")
+	f.write("worktrees/wt/HEAD", "ref: refs/heads/feature\n")
+	f.write("worktrees/wt/commondir", "../..\n")
+
+	wt := filepath.Join(t.TempDir(), "wt")
+	if err := os.MkdirAll(wt, 0755); err != nil {
+		t.Fatal(err)
+	}
+	gitFile := "gitdir: " + filepath.Join(f.git, "worktrees", "wt") + "\n"
+	if err := os.WriteFile(filepath.Join(wt, ".git"), []byte(gitFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Open(wt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if branch, commit, err := r.Head(); branch != "feature" || commit != feature || err != nil {
+		t.Errorf("Head() = %q, %q, %v, want feature, %s", branch, commit, err, feature)
+	}
+	if url := r.RemoteURL("origin"); url != "https://example.com/repo.git" {
+		t.Errorf("RemoteURL() = %q, want the main repository's remote", url)
+	}
+}
+
+func TestOpen_RelativeGitFile(t *testing.T) {
+	// submodules point to ../.git/modules/<name> with a relative path
+	f := newFixture(t)
+	f.write("modules/sub/HEAD", "ref: refs/heads/main\n")
+	for _, d := range []string{"objects", "refs"} {
+		if err := os.MkdirAll(filepath.Join(f.git, "modules", "sub", d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sub := filepath.Join(f.dir, "sub")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, ".git"), []byte("gitdir: ../.git/modules/sub\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if want := filepath.Join(f.git, "modules", "sub"); r.GitDir() != want {
+		t.Errorf("GitDir() = %q, want %q", r.GitDir(), want)
+	}
+}
+
+func TestRemoteURL_Config(t *testing.T) {
+	f := newFixture(t)
+	f.write("config", `[core]
+	bare = false
+	logallrefupdates
+[remote "origin"]
+	URL = "git@github.com:org/app.git" ; comment
+	fetch = +refs/heads/*:refs/remotes/origin/*
+[Remote "Upstream"]
+	url = https://example.com/a\"b # trailing
+[remote.legacy]
+	url = https://example.com/legacy
+`)
+	r := f.open()
+	tests := map[string]string{
+		"origin":   "git@github.com:org/app.git",
+		"Upstream": `https://example.com/a"b`,
+		"legacy":   "https://example.com/legacy",
+		"missing":  "",
+	}
+	for name, want := range tests {
+		if got := r.RemoteURL(name); got != want {
+			t.Errorf("RemoteURL(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if r.config["core.logallrefupdates"] != "true" {
+		t.Errorf("key without value should be true, got %q", r.config["core.logallrefupdates"])
+	}
+}
@@ -0,0 +1,264 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MarshalText implements encoding.TextMarshaler. A version encodes as its
+// canonical form, see String; the zero Version encodes as "" and a Version
+// holding only a Raw string that is not a strict semantic version, such as a
+// bare commit name or "1.2", encodes as Raw.
+func (ver Version) MarshalText() ([]byte, error) {
+	if ver.Major == 0 && ver.Minor == 0 && ver.Patch == 0 && ver.PrereleaseString() == "" && len(ver.Build) == 0 {
+		if ver.Raw == "" {
+			return []byte{}, nil
+		}
+		if _, err := Parse(ver.Raw); err != nil {
+			return []byte(ver.Raw), nil
+		}
+	}
+	return []byte(ver.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so a Version can be used
+// with flag.TextVar, encoding/json and configuration libraries. The text is
+// parsed with Parse; empty text yields the zero Version.
+func (ver *Version) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*ver = Version{}
+		return nil
+	}
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*ver = v
+	return nil
+}
+
+// appJSON is the JSON encoding of AppInfo.
+type appJSON struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Changelog   string `json:"changelog,omitempty"`
+}
+
+func (app AppInfo) toJSON() appJSON {
+	return appJSON{Name: app.Name, Description: app.Description, Changelog: app.Changelog}
+}
+
+func (j appJSON) appInfo() AppInfo {
+	app := AppInfo{Name: j.Name, Description: j.Description, Changelog: j.Changelog}
+	if j.Changelog != "" {
+		app.Changes, _ = ParseChangelog(j.Changelog)
+	}
+	return app
+}
+
+// MarshalJSON encodes app as {"name", "description", "changelog"}, omitting
+// empty fields. Changes is not encoded, it is parsed from the changelog.
+func (app AppInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(app.toJSON())
+}
+
+// UnmarshalJSON decodes the encoding of MarshalJSON. A changelog that cannot
+// be parsed leaves Changes empty.
+func (app *AppInfo) UnmarshalJSON(data []byte) error {
+	var j appJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*app = j.appInfo()
+	return nil
+}
+
+// gitJSON is the JSON encoding of GitInfo.
+type gitJSON struct {
+	Commit   string    `json:"commit,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	Repo     string    `json:"repo,omitempty"`
+	Dirty    bool      `json:"dirty,omitempty"`
+	Describe *Describe `json:"describe,omitempty"`
+}
+
+func (git GitInfo) toJSON() gitJSON {
+	j := gitJSON{Commit: git.Commit, Branch: git.Branch, Repo: git.Repo, Dirty: git.Dirty}
+	if !git.Describe.IsZero() {
+		d := git.Describe
+		j.Describe = &d
+	}
+	return j
+}
+
+func (j gitJSON) gitInfo() GitInfo {
+	git := GitInfo{Commit: j.Commit, Branch: j.Branch, Repo: j.Repo, Dirty: j.Dirty}
+	if j.Describe != nil {
+		git.Describe = *j.Describe
+	}
+	return git
+}
+
+func (j gitJSON) isZero() bool {
+	return j == gitJSON{}
+}
+
+// MarshalJSON encodes git as {"commit", "branch", "repo", "dirty",
+// "describe"}, omitting empty fields.
+func (git GitInfo) MarshalJSON() ([]byte, error) {
+	return json.Marshal(git.toJSON())
+}
+
+// UnmarshalJSON decodes the encoding of MarshalJSON.
+func (git *GitInfo) UnmarshalJSON(data []byte) error {
+	var j gitJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*git = j.gitInfo()
+	return nil
+}
+
+// buildJSON is the JSON encoding of BuildInfo.
+type buildJSON struct {
+	Timestamp string            `json:"timestamp,omitempty"`
+	Git       *gitJSON          `json:"git,omitempty"`
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+// formatTimestamp renders t in RFC 3339, "" for the zero time.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseJSONTimestamp parses an RFC 3339 timestamp, "" is the zero time.
+func parseJSONTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("build timestamp: %w", err)
+	}
+	return t, nil
+}
+
+// MarshalJSON encodes build as {"timestamp", "git", "extra"} with the
+// timestamp in RFC 3339, omitting empty fields.
+func (build BuildInfo) MarshalJSON() ([]byte, error) {
+	j := buildJSON{Timestamp: formatTimestamp(build.Timestamp), Extra: build.Extra}
+	if git := build.Git.toJSON(); !git.isZero() {
+		j.Git = &git
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes the encoding of MarshalJSON.
+func (build *BuildInfo) UnmarshalJSON(data []byte) error {
+	var j buildJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	t, err := parseJSONTimestamp(j.Timestamp)
+	if err != nil {
+		return err
+	}
+	*build = BuildInfo{Timestamp: t, Extra: j.Extra}
+	if j.Git != nil {
+		build.Git = j.Git.gitInfo()
+	}
+	return nil
+}
+
+// Metadata is a consistent copy of the application, version and build info
+// of an Info, as returned by Snapshot. Its JSON encoding extends the version
+// file schema (see File) with the changelog and the git describe fields:
+//
+//	{
+//	  "app": {"name": "myapp", "description": "My application"},
+//	  "version": "1.2.3",
+//	  "git": {"commit": "abc1234", "branch": "main", "dirty": true},
+//	  "build_timestamp": "2024-01-15T10:30:00Z",
+//	  "extra": {"pipeline": "1234"}
+//	}
+type Metadata struct {
+	App     AppInfo
+	Version Version
+	Build   BuildInfo
+}
+
+// metadataJSON is the JSON encoding of Metadata.
+type metadataJSON struct {
+	App            *appJSON          `json:"app,omitempty"`
+	Version        string            `json:"version,omitempty"`
+	Git            *gitJSON          `json:"git,omitempty"`
+	BuildTimestamp string            `json:"build_timestamp,omitempty"`
+	Extra          map[string]string `json:"extra,omitempty"`
+}
+
+// MarshalJSON encodes m as described on Metadata, omitting empty fields.
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	ver, err := m.Version.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	j := metadataJSON{
+		Version:        string(ver),
+		BuildTimestamp: formatTimestamp(m.Build.Timestamp),
+		Extra:          m.Build.Extra,
+	}
+	if app := m.App.toJSON(); app != (appJSON{}) {
+		j.App = &app
+	}
+	if git := m.Build.Git.toJSON(); !git.isZero() {
+		j.Git = &git
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes the encoding of MarshalJSON; it also accepts JSON
+// version files. A version that is not a semantic version, such as a bare
+// commit name, is kept in Version.Raw.
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	var j metadataJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	t, err := parseJSONTimestamp(j.BuildTimestamp)
+	if err != nil {
+		return err
+	}
+	var ver Version
+	if err := ver.UnmarshalText([]byte(j.Version)); err != nil {
+		ver = Version{Raw: j.Version}
+	}
+	*m = Metadata{Version: ver, Build: BuildInfo{Timestamp: t, Extra: j.Extra}}
+	if j.App != nil {
+		m.App = j.App.appInfo()
+	}
+	if j.Git != nil {
+		m.Build.Git = j.Git.gitInfo()
+	}
+	return nil
+}
+
+// File returns the version file fields of m, with the version in canonical
+// form and the build timestamp in RFC 3339.
+func (m Metadata) File() File {
+	ver, _ := m.Version.MarshalText()
+	return File{
+		AppName:        m.App.Name,
+		AppDescription: m.App.Description,
+		Version:        string(ver),
+		Commit:         m.Build.Git.Commit,
+		Branch:         m.Build.Git.Branch,
+		Repo:           m.Build.Git.Repo,
+		Dirty:          formatDirty(m.Build.Git.Dirty),
+		BuildTimestamp: formatTimestamp(m.Build.Timestamp),
+		Extra:          m.Build.Extra,
+	}
+}
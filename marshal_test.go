@@ -0,0 +1,175 @@
+package version
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestVersion_MarshalText(t *testing.T) {
+	tests := []struct {
+		v    Version
+		want string
+	}{
+		{MustParse("v1.2.3-rc.1+build.42"), "1.2.3-rc.1+build.42"},
+		{MustParse("0.0.0"), "0.0.0"},
+		{Version{}, ""},
+		{Version{Raw: "abc1234"}, "abc1234"},
+		{Version{Raw: "1.2"}, "1.2"},
+		{Version{Raw: "01.2.3"}, "01.2.3"},
+	}
+	for _, tt := range tests {
+		got, err := tt.v.MarshalText()
+		if err != nil || string(got) != tt.want {
+			t.Errorf("MarshalText(%q) = %q, %v, want %q", tt.v.Raw, got, err, tt.want)
+		}
+	}
+}
+
+func TestVersion_UnmarshalText(t *testing.T) {
+	var v Version
+	if err := v.UnmarshalText([]byte("v1.2.3-rc.1")); err != nil || v.Compare(MustParse("1.2.3-rc.1")) != 0 {
+		t.Errorf("UnmarshalText() = %v, %v", v, err)
+	}
+	if err := v.UnmarshalText(nil); err != nil || !reflect.DeepEqual(v, Version{}) {
+		t.Errorf("UnmarshalText(empty) = %+v, %v, want the zero Version", v, err)
+	}
+	if err := v.UnmarshalText([]byte("1.2")); err == nil {
+		t.Error("UnmarshalText(1.2) should fail")
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.TextVar(&v, "min-version", MustParse("1.0.0"), "")
+	if err := fs.Parse([]string{"--min-version", "2.1.0"}); err != nil || v.String() != "2.1.0" {
+		t.Errorf("flag.TextVar = %v, %v", v, err)
+	}
+}
+
+func TestVersion_JSON(t *testing.T) {
+	type config struct {
+		Min Version            `json:"min"`
+		Max map[string]Version `json:"max"`
+	}
+	in := config{Min: MustParse("v1.2.3"), Max: map[string]Version{"api": MustParse("2.0.0")}}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"min":"1.2.3","max":{"api":"2.0.0"}}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+	var out config
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Min.Equal(in.Min) || !out.Max["api"].Equal(in.Max["api"]) {
+		t.Errorf("Unmarshal() = %+v, want %+v", out, in)
+	}
+	if err := json.Unmarshal([]byte(`{"min":"not a version"}`), &out); err == nil {
+		t.Error("Unmarshal() of an invalid version should fail")
+	}
+}
+
+func TestBuildInfo_JSON(t *testing.T) {
+	build := BuildInfo{
+		Timestamp: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Git:       GitInfo{Commit: "abc1234", Dirty: true, Describe: Describe{Tag: "v1.2.3", Distance: 4, ShortHash: "abc1234"}},
+		Extra:     map[string]string{"pipeline": "1234"},
+	}
+	data, err := json.Marshal(build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"timestamp":"2024-01-15T10:30:00Z","git":{"commit":"abc1234","dirty":true,` +
+		`"describe":{"tag":"v1.2.3","distance":4,"short_hash":"abc1234"}},"extra":{"pipeline":"1234"}}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+	var got BuildInfo
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, build) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, build)
+	}
+
+	if data, _ := json.Marshal(BuildInfo{}); string(data) != "{}" {
+		t.Errorf("Marshal(BuildInfo{}) = %s, want {}", data)
+	}
+	if err := json.Unmarshal([]byte(`{"timestamp":"yesterday"}`), &got); err == nil {
+		t.Error("Unmarshal() of a timestamp that is not RFC 3339 should fail")
+	}
+}
+
+func TestAppInfo_JSON(t *testing.T) {
+	app := AppInfo{Name: "myapp", Changelog: "## [1.0.0]\n\n### Added\n\n- First release\n"}
+	data, err := json.Marshal(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"myapp","changelog":"## [1.0.0]\n\n### Added\n\n- First release\n"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+	var got AppInfo
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "myapp" || len(got.Changes.Releases) != 1 {
+		t.Errorf("Unmarshal() = %+v, want the changelog parsed", got)
+	}
+}
+
+func TestSnapshot_JSON(t *testing.T) {
+	i := New(
+		WithAppInfo("myapp", "My application"),
+		WithVersion("v1.2.3-4-gabc1234"),
+		WithGitInfo("abc1234def", "main", ""),
+		WithBuildInfo("2024-01-15T10:30:00Z"),
+	)
+	i.SetExtra("pipeline", "1234")
+	m := i.Snapshot()
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"app":{"name":"myapp","description":"My application"},"version":"1.2.4-dev.4+gabc1234",` +
+		`"git":{"commit":"abc1234def","branch":"main","describe":{"tag":"v1.2.3","distance":4,"short_hash":"abc1234"}},` +
+		`"build_timestamp":"2024-01-15T10:30:00Z","extra":{"pipeline":"1234"}}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s\nwant %s", data, want)
+	}
+
+	var got Metadata
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Version.Equal(m.Version) || !reflect.DeepEqual(got.App, m.App) || got.Build.Git != m.Build.Git ||
+		!got.Build.Timestamp.Equal(m.Build.Timestamp) || !reflect.DeepEqual(got.Build.Extra, m.Build.Extra) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, m)
+	}
+
+	// the encoding is a JSON version file
+	f, err := ParseFile(data, FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f, m.File()) {
+		t.Errorf("ParseFile() = %+v, want %+v", f, m.File())
+	}
+
+	if data, _ := json.Marshal(Metadata{}); string(data) != "{}" {
+		t.Errorf("Marshal(Metadata{}) = %s, want {}", data)
+	}
+	// SetVersion keeps a version that is not strictly semantic in Raw only
+	for _, ver := range []string{"1.2", "v1", "01.2.3"} {
+		data, err := json.Marshal(New(WithVersion(ver)).Snapshot())
+		if want := `{"version":"` + ver + `"}`; err != nil || string(data) != want {
+			t.Errorf("Marshal() of version %q = %s, %v, want %s", ver, data, err, want)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"version":"abc1234"}`), &got); err != nil || got.Version.Raw != "abc1234" {
+		t.Errorf("Unmarshal() of a commit name = %+v, %v", got.Version, err)
+	}
+}
@@ -0,0 +1,176 @@
+package version
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// Default response headers set by Middleware.
+const (
+	DefaultVersionHeader = "X-App-Version"
+	DefaultCommitHeader  = "X-Git-Commit"
+)
+
+// userAgentAbbrev is the length of the commit name in the User-Agent.
+const userAgentAbbrev = 7
+
+// MiddlewareOption configures a Middleware.
+type MiddlewareOption func(*middleware)
+
+// VersionHeader sets the response header that carries the version, "" to
+// leave it out. The default is DefaultVersionHeader.
+func VersionHeader(name string) MiddlewareOption {
+	return func(m *middleware) { m.versionHeader = name }
+}
+
+// CommitHeader sets the response header that carries the git commit, "" to
+// leave it out. The default is DefaultCommitHeader.
+func CommitHeader(name string) MiddlewareOption {
+	return func(m *middleware) { m.commitHeader = name }
+}
+
+// headerValues are the header strings of one snapshot.
+type headerValues struct {
+	state     *state
+	version   string
+	commit    string
+	userAgent string
+}
+
+// headerCache formats the header strings once per snapshot of an Info.
+type headerCache struct {
+	info   *Info
+	values atomic.Pointer[headerValues]
+}
+
+// get returns the header strings of the current snapshot.
+func (c *headerCache) get() *headerValues {
+	s := c.info.load()
+	if v := c.values.Load(); v != nil && v.state == s {
+		return v
+	}
+	ver, _ := s.version.MarshalText()
+	v := &headerValues{
+		state:     s,
+		version:   string(ver),
+		commit:    s.build.Git.Commit,
+		userAgent: userAgent(s.app.Name, string(ver), s.build.Git.Commit),
+	}
+	c.values.Store(v)
+	return v
+}
+
+// userAgent formats a User-Agent such as
+// "myapp/1.2.3 (commit abc1234; go1.22.1; linux/amd64)".
+func userAgent(name, ver, commit string) string {
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+	var sb strings.Builder
+	sb.WriteString(strings.ReplaceAll(name, " ", "-"))
+	if ver != "" {
+		sb.WriteString("/")
+		sb.WriteString(ver)
+	}
+	sb.WriteString(" (")
+	if commit != "" {
+		if len(commit) > userAgentAbbrev {
+			commit = commit[:userAgentAbbrev]
+		}
+		sb.WriteString("commit ")
+		sb.WriteString(commit)
+		sb.WriteString("; ")
+	}
+	sb.WriteString(runtime.Version())
+	sb.WriteString("; ")
+	sb.WriteString(runtime.GOOS + "/" + runtime.GOARCH)
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// middleware sets the version headers of responses, see Middleware.
+type middleware struct {
+	cache         *headerCache
+	versionHeader string
+	commitHeader  string
+}
+
+// Middleware returns HTTP middleware that stamps responses with the version
+// of the default Info, see Info.Middleware.
+func Middleware(opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	return std.Middleware(opts...)
+}
+
+// Middleware returns HTTP middleware that sets the X-App-Version and
+// X-Git-Commit response headers from i, so that every response identifies
+// the build that served it:
+//
+//	handler = version.Middleware()(handler)
+//
+// The header names are set with VersionHeader and CommitHeader. Headers with
+// an empty value are left out. The header strings are formatted once per
+// update of i, not per request.
+func (i *Info) Middleware(opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	m := &middleware{
+		cache:         &headerCache{info: i},
+		versionHeader: DefaultVersionHeader,
+		commitHeader:  DefaultCommitHeader,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v := m.cache.get()
+			header := w.Header()
+			if m.versionHeader != "" && v.version != "" {
+				header.Set(m.versionHeader, v.version)
+			}
+			if m.commitHeader != "" && v.commit != "" {
+				header.Set(m.commitHeader, v.commit)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// transport sets the User-Agent of requests, see Transport.
+type transport struct {
+	cache *headerCache
+	base  http.RoundTripper
+}
+
+// Transport returns an http.RoundTripper that identifies requests with the
+// version of the default Info, see Info.Transport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	return std.Transport(base)
+}
+
+// Transport returns an http.RoundTripper that sets the User-Agent of
+// requests without one to the application name, version and commit of i,
+// e.g. "myapp/1.2.3 (commit abc1234; go1.22.1; linux/amd64)", and passes
+// them to base, http.DefaultTransport if nil:
+//
+//	client := &http.Client{Transport: version.Transport(nil)}
+//
+// Without an application name the executable name is used.
+func (i *Info) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{cache: &headerCache{info: i}, base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") != "" {
+		return t.base.RoundTrip(req)
+	}
+	// a RoundTripper must not modify the caller's request
+	r := req.Clone(req.Context())
+	r.Header.Set("User-Agent", t.cache.get().userAgent)
+	return t.base.RoundTrip(r)
+}
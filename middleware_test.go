@@ -0,0 +1,131 @@
+package version
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	i := New(WithVersion("v1.2.3"), WithGitInfo("abc1234def", "main", ""))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	rec := httptest.NewRecorder()
+	i.Middleware()(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusTeapot {
+		t.Errorf("status = %d, want the wrapped handler's", rec.Code)
+	}
+	if got := rec.Header().Get("X-App-Version"); got != "1.2.3" {
+		t.Errorf("X-App-Version = %q, want 1.2.3", got)
+	}
+	if got := rec.Header().Get("X-Git-Commit"); got != "abc1234def" {
+		t.Errorf("X-Git-Commit = %q, want abc1234def", got)
+	}
+
+	h := i.Middleware(VersionHeader("X-Build"), CommitHeader(""))(next)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Header().Get("X-Build") != "1.2.3" || rec.Header().Get("X-App-Version") != "" || rec.Header().Get("X-Git-Commit") != "" {
+		t.Errorf("custom headers = %v", rec.Header())
+	}
+
+	// an update is picked up by the next request
+	if err := i.SetVersion("v1.3.0"); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := rec.Header().Get("X-Build"); got != "1.3.0" {
+		t.Errorf("X-Build after SetVersion = %q, want 1.3.0", got)
+	}
+
+	rec = httptest.NewRecorder()
+	New().Middleware()(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(rec.Header()) != 0 {
+		t.Errorf("headers of an empty Info = %v, want none", rec.Header())
+	}
+}
+
+func TestHeaderCache(t *testing.T) {
+	c := &headerCache{info: New(WithVersion("1.0.0"))}
+	first := c.get()
+	if c.get() != first {
+		t.Error("get() formatted the headers again for the same snapshot")
+	}
+	c.info.SetExtra("k", "v")
+	if c.get() == first {
+		t.Error("get() returned the headers of an old snapshot")
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	platform := runtime.Version() + "; " + runtime.GOOS + "/" + runtime.GOARCH
+	tests := []struct {
+		name, ver, commit, want string
+	}{
+		{"myapp", "1.2.3", "abc1234def", "myapp/1.2.3 (commit abc1234; " + platform + ")"},
+		{"my app", "", "", "my-app (" + platform + ")"},
+	}
+	for _, tt := range tests {
+		if got := userAgent(tt.name, tt.ver, tt.commit); got != tt.want {
+			t.Errorf("userAgent(%q, %q, %q) = %q, want %q", tt.name, tt.ver, tt.commit, got, tt.want)
+		}
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestTransport(t *testing.T) {
+	var got string
+	base := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		got = r.Header.Get("User-Agent")
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: r}, nil
+	})
+	i := New(WithAppInfo("myapp", ""), WithVersion("v1.2.3"), WithGitInfo("abc1234def", "", ""))
+	client := &http.Client{Transport: i.Transport(base)}
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.RequestURI = ""
+	if _, err := client.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	if want := userAgent("myapp", "1.2.3", "abc1234def"); got != want {
+		t.Errorf("User-Agent = %q, want %q", got, want)
+	}
+	if req.Header.Get("User-Agent") != "" {
+		t.Error("Transport modified the caller's request")
+	}
+
+	req.Header.Set("User-Agent", "custom/1.0")
+	if _, err := client.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	if got != "custom/1.0" {
+		t.Errorf("User-Agent = %q, want the request's own", got)
+	}
+}
+
+func TestTransport_Server(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.UserAgent()
+	}))
+	defer srv.Close()
+
+	i := New(WithAppInfo("myapp", ""), WithVersion("2.0.0"))
+	client := &http.Client{Transport: i.Transport(nil)}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if want := userAgent("myapp", "2.0.0", ""); got != want {
+		t.Errorf("server saw User-Agent %q, want %q", got, want)
+	}
+}
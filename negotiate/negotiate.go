@@ -0,0 +1,241 @@
+// Package negotiate enforces a compatibility window between clients and a
+// server, based on the version each client reports.
+//
+// The server declares the client versions it supports, and optionally the
+// supported versions it is about to drop, as constraint expressions. The
+// middleware reads the client version from a request header, rejects
+// unsupported clients with 426 Upgrade Required and a JSON body, and flags
+// deprecated ones with a Deprecation header (RFC 9745):
+//
+//	policy := negotiate.Window(version.Get(), 2) // within two minor versions
+//	handler = negotiate.Middleware(policy)(handler)
+//
+// A rejected client receives:
+//
+//	HTTP/1.1 426 Upgrade Required
+//	Upgrade: myapp/1.5.2
+//	Content-Type: application/json
+//
+//	{"error":"version 1.1.0 does not satisfy \">=1.3.0 <2.0.0\": ...",
+//	 "client_version":"1.1.0","supported":">=1.3.0 <2.0.0","server_version":"1.5.2"}
+package negotiate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	version "github.com/rbaliyan/go-version"
+	"github.com/rbaliyan/go-version/constraint"
+)
+
+// DefaultHeader is the request header clients report their version in.
+const DefaultHeader = "X-Client-Version"
+
+// Policy declares the client versions a server accepts.
+type Policy struct {
+	// client versions that are accepted, all versions if nil
+	Supported *constraint.Constraint
+	// accepted client versions that get a Deprecation header, none if nil
+	Deprecated *constraint.Constraint
+	// date sent in the Deprecation header, the time of the request if zero
+	DeprecatedSince time.Time
+	// request header with the client version, DefaultHeader if empty
+	Header string
+	// if set, the version is also read from a "<Product>/<version>" token of
+	// the User-Agent, as sent by version.Transport
+	Product string
+	// reject requests that do not report a version; by default they are
+	// passed through
+	Required bool
+	// compare client versions by major.minor.patch only, so that prerelease
+	// and development builds such as 1.5.3-dev.4+gabc1234 are accepted by
+	// ranges of releases
+	AllowPrerelease bool
+	// value of the Upgrade header of 426 responses, "<app name>/<version>"
+	// of Server if empty
+	Upgrade string
+	// server version reported in rejections, version.Default() if nil
+	Server *version.Info
+}
+
+// Window returns a policy that accepts clients of the server's major version
+// that are at most minors minor versions behind it, and warns clients of the
+// oldest accepted minor version. For server 1.5.2 and two minor versions,
+// 1.3.0 up to 2.0.0 are accepted and 1.3.x is deprecated. Prerelease clients
+// are compared by major.minor.patch, see Policy.AllowPrerelease.
+//
+// A zero server version, as returned for an unset or unparsable version,
+// gives no window: the returned policy accepts every client.
+func Window(server version.Version, minors int) Policy {
+	if server.Major == 0 && server.Minor == 0 && server.Patch == 0 {
+		return Policy{}
+	}
+	oldest := server.Minor - minors
+	if oldest < 0 {
+		oldest = 0
+	}
+	p := Policy{
+		Supported:       constraint.MustParse(fmt.Sprintf(">=%d.%d.0 <%d.0.0", server.Major, oldest, server.Major+1)),
+		AllowPrerelease: true,
+	}
+	if oldest < server.Minor {
+		p.Deprecated = constraint.MustParse(fmt.Sprintf("~%d.%d", server.Major, oldest))
+	}
+	return p
+}
+
+// Check returns nil if the policy accepts client version v, otherwise a
+// *constraint.UnsatisfiedError.
+func (p Policy) Check(v version.Version) error {
+	if p.Supported == nil {
+		return nil
+	}
+	return p.Supported.Validate(p.compared(v))
+}
+
+// IsDeprecated reports whether client version v is accepted but will stop
+// being supported.
+func (p Policy) IsDeprecated(v version.Version) bool {
+	return p.Deprecated != nil && p.Check(v) == nil && p.Deprecated.Check(p.compared(v))
+}
+
+// compared returns the version the constraints are evaluated against.
+func (p Policy) compared(v version.Version) version.Version {
+	if !p.AllowPrerelease {
+		return v
+	}
+	core := version.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	core.Raw = core.String()
+	return core
+}
+
+// Rejection is the JSON body of a rejected request.
+type Rejection struct {
+	// why the request was rejected
+	Error string `json:"error"`
+	// version the client reported, nil if it reported none
+	ClientVersion *version.Version `json:"client_version,omitempty"`
+	// constraint expression of the supported client versions
+	Supported string `json:"supported,omitempty"`
+	// version of the server, nil if unknown
+	ServerVersion *version.Version `json:"server_version,omitempty"`
+}
+
+type contextKey struct{}
+
+// ClientVersion returns the client version read by Middleware.
+func ClientVersion(ctx context.Context) (version.Version, bool) {
+	v, ok := ctx.Value(contextKey{}).(version.Version)
+	return v, ok
+}
+
+// Middleware returns HTTP middleware that enforces p. Accepted requests are
+// passed on with the client version in their context, see ClientVersion;
+// deprecated clients also get a Deprecation header such as "@1704067200",
+// see Policy.DeprecatedSince. Clients with
+// an unsupported version get 426 Upgrade Required with an Upgrade header, a
+// malformed or, if required, missing version 400 Bad Request, both with a
+// Rejection body.
+func Middleware(p Policy) func(http.Handler) http.Handler {
+	header := p.Header
+	if header == "" {
+		header = DefaultHeader
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw := p.clientVersion(r, header)
+			if raw == "" {
+				if p.Required {
+					p.reject(w, http.StatusBadRequest, Rejection{Error: "missing client version in " + header})
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			v, err := version.ParseWithMode(raw, version.Lenient)
+			if err != nil {
+				p.reject(w, http.StatusBadRequest, Rejection{Error: err.Error()})
+				return
+			}
+			if err := p.Check(v); err != nil {
+				p.reject(w, http.StatusUpgradeRequired, Rejection{Error: err.Error(), ClientVersion: &v})
+				return
+			}
+			if p.IsDeprecated(v) {
+				since := p.DeprecatedSince
+				if since.IsZero() {
+					since = time.Now()
+				}
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(since.Unix(), 10))
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, v)))
+		})
+	}
+}
+
+// clientVersion returns the version reported by the client, "" if none.
+func (p Policy) clientVersion(r *http.Request, header string) string {
+	if v := strings.TrimSpace(r.Header.Get(header)); v != "" {
+		return v
+	}
+	if p.Product == "" {
+		return ""
+	}
+	for _, token := range strings.Fields(r.UserAgent()) {
+		if product, v, ok := strings.Cut(token, "/"); ok && product == p.Product {
+			return v
+		}
+	}
+	return ""
+}
+
+// upgrade returns the Upgrade header value, by default the product token of
+// the server, e.g. "myapp/1.5.2".
+func (p Policy) upgrade(name string, v version.Version) string {
+	if p.Upgrade != "" {
+		return p.Upgrade
+	}
+	if name == "" {
+		name = filepath.Base(os.Args[0])
+	}
+	token := strings.ReplaceAll(name, " ", "-")
+	if ver, _ := v.MarshalText(); len(ver) > 0 {
+		token += "/" + string(ver)
+	}
+	return token
+}
+
+// reject writes a Rejection with the supported range and server version.
+func (p Policy) reject(w http.ResponseWriter, status int, body Rejection) {
+	if p.Supported != nil {
+		body.Supported = p.Supported.String()
+	}
+	server := p.Server
+	if server == nil {
+		server = version.Default()
+	}
+	v := server.Get()
+	if v.Raw != "" {
+		body.ServerVersion = &v
+	}
+	if status == http.StatusUpgradeRequired {
+		// RFC 9110 requires an Upgrade header in 426 responses
+		w.Header().Set("Upgrade", p.upgrade(server.App().Name, v))
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(data, '\n'))
+}
@@ -0,0 +1,183 @@
+package negotiate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	version "github.com/rbaliyan/go-version"
+	"github.com/rbaliyan/go-version/constraint"
+)
+
+func TestWindow(t *testing.T) {
+	p := Window(version.MustParse("1.5.2"), 2)
+	if p.Supported.String() != ">=1.3.0 <2.0.0" || p.Deprecated.String() != "~1.3" {
+		t.Errorf("Window(1.5.2, 2) = %q, %q", p.Supported, p.Deprecated)
+	}
+	tests := []struct {
+		client     string
+		ok         bool
+		deprecated bool
+	}{
+		{"1.5.2", true, false},
+		{"1.6.0", true, false},
+		{"1.4.9", true, false},
+		{"1.3.0", true, true},
+		{"1.3.7", true, true},
+		{"1.2.9", false, false},
+		{"2.0.0", false, false},
+		{"0.9.0", false, false},
+		// development builds derived from git describe
+		{"1.5.3-dev.4+gabc1234", true, false},
+		{"1.3.1-rc.1", true, true},
+		{"1.3.0-dev.2", true, true},
+		{"2.0.0-rc.1", false, false},
+	}
+	for _, tt := range tests {
+		v := version.MustParse(tt.client)
+		if err := p.Check(v); (err == nil) != tt.ok {
+			t.Errorf("Check(%s) = %v, want ok %v", tt.client, err, tt.ok)
+		}
+		if got := p.IsDeprecated(v); got != tt.deprecated {
+			t.Errorf("IsDeprecated(%s) = %v, want %v", tt.client, got, tt.deprecated)
+		}
+	}
+
+	strict := Policy{Supported: p.Supported}
+	if err := strict.Check(version.MustParse("1.5.3-dev.4+gabc1234")); err == nil {
+		t.Error("Check() without AllowPrerelease should reject a prerelease of another release")
+	}
+
+	p = Window(version.MustParse("1.1.0"), 3)
+	if p.Supported.String() != ">=1.0.0 <2.0.0" || p.Deprecated.String() != "~1.0" {
+		t.Errorf("Window(1.1.0, 3) = %q, %q", p.Supported, p.Deprecated)
+	}
+	if p = Window(version.MustParse("1.1.0"), 0); p.Deprecated != nil {
+		t.Errorf("Window(1.1.0, 0) deprecates %q", p.Deprecated)
+	}
+
+	// an unknown server version must not reject every 1.x client
+	for _, server := range []version.Version{{}, {Raw: "not-a-version"}} {
+		p = Window(server, 2)
+		if p.Supported != nil || p.Deprecated != nil || p.Check(version.MustParse("1.4.0")) != nil {
+			t.Errorf("Window(%q, 2) = %q, %q, want no policy", server.Raw, p.Supported, p.Deprecated)
+		}
+	}
+}
+
+// serve sends a request with the given headers through Middleware(p).
+func serve(p Policy, header map[string]string) (*httptest.ResponseRecorder, *version.Version) {
+	var seen *version.Version
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v, ok := ClientVersion(r.Context()); ok {
+			seen = &v
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	Middleware(p)(next).ServeHTTP(rec, req)
+	return rec, seen
+}
+
+func TestMiddleware(t *testing.T) {
+	p := Window(version.MustParse("1.5.2"), 2)
+	p.Server = version.New(version.WithVersion("v1.5.2"))
+
+	rec, seen := serve(p, map[string]string{"X-Client-Version": "1.4.0"})
+	if rec.Code != http.StatusNoContent || seen == nil || seen.String() != "1.4.0" {
+		t.Errorf("supported client = %d, context version %v", rec.Code, seen)
+	}
+	if d := rec.Header().Get("Deprecation"); d != "" {
+		t.Errorf("supported client got Deprecation %q", d)
+	}
+
+	rec, _ = serve(p, map[string]string{"X-Client-Version": "1.5.3-dev.4+gabc1234"})
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("development build = %d, want it passed on", rec.Code)
+	}
+
+	rec, _ = serve(p, map[string]string{"X-Client-Version": "v1.3"})
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("deprecated client = %d, want it passed on", rec.Code)
+	}
+	if d := rec.Header().Get("Deprecation"); !regexp.MustCompile(`^@\d+$`).MatchString(d) {
+		t.Errorf("deprecated client Deprecation = %q, want @<unix time>", d)
+	}
+
+	p.DeprecatedSince = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rec, _ = serve(p, map[string]string{"X-Client-Version": "1.3.2"})
+	if d := rec.Header().Get("Deprecation"); d != "@1704067200" {
+		t.Errorf("Deprecation = %q, want @1704067200", d)
+	}
+	p.DeprecatedSince = time.Time{}
+
+	rec, seen = serve(p, nil)
+	if rec.Code != http.StatusNoContent || seen != nil {
+		t.Errorf("client without a version = %d, context version %v", rec.Code, seen)
+	}
+}
+
+func TestMiddleware_Reject(t *testing.T) {
+	p := Window(version.MustParse("1.5.2"), 2)
+	p.Server = version.New(version.WithAppInfo("myapp", ""), version.WithVersion("v1.5.2"))
+
+	rec, _ := serve(p, map[string]string{"X-Client-Version": "1.1.0"})
+	if rec.Code != http.StatusUpgradeRequired || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unsupported client = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if got := rec.Header().Get("Upgrade"); got != "myapp/1.5.2" {
+		t.Errorf("Upgrade = %q, want myapp/1.5.2", got)
+	}
+	var body Rejection
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body is not a Rejection: %v\n%s", err, rec.Body)
+	}
+	if body.ClientVersion == nil || body.ClientVersion.String() != "1.1.0" ||
+		body.ServerVersion == nil || body.ServerVersion.String() != "1.5.2" ||
+		body.Supported != ">=1.3.0 <2.0.0" || !strings.Contains(body.Error, "1.1.0") {
+		t.Errorf("Rejection = %s", rec.Body)
+	}
+
+	rec, _ = serve(p, map[string]string{"X-Client-Version": "latest"})
+	if rec.Code != http.StatusBadRequest || rec.Header().Get("Upgrade") != "" {
+		t.Errorf("malformed client version = %d, Upgrade %q, want 400 without Upgrade", rec.Code, rec.Header().Get("Upgrade"))
+	}
+
+	p.Upgrade = "myapp/2"
+	rec, _ = serve(p, map[string]string{"X-Client-Version": "1.1.0"})
+	if got := rec.Header().Get("Upgrade"); got != "myapp/2" {
+		t.Errorf("Upgrade = %q, want the policy's", got)
+	}
+
+	p.Required = true
+	rec, _ = serve(p, nil)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "missing client version in X-Client-Version") {
+		t.Errorf("required version missing = %d %s", rec.Code, rec.Body)
+	}
+}
+
+func TestMiddleware_UserAgent(t *testing.T) {
+	p := Policy{Supported: constraint.MustParse(">=2.0.0"), Product: "myapp", Header: "X-App-Client"}
+	rec, seen := serve(p, map[string]string{"User-Agent": "myapp/2.1.0 (commit abc1234; go1.22.1; linux/amd64)"})
+	if rec.Code != http.StatusNoContent || seen == nil || seen.String() != "2.1.0" {
+		t.Errorf("User-Agent version = %d, context version %v", rec.Code, seen)
+	}
+
+	rec, _ = serve(p, map[string]string{"User-Agent": "myapp/1.0.0", "X-App-Client": "2.0.0"})
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("the header should win over the User-Agent, got %d", rec.Code)
+	}
+
+	rec, seen = serve(p, map[string]string{"User-Agent": "other/1.0.0"})
+	if rec.Code != http.StatusNoContent || seen != nil {
+		t.Errorf("User-Agent of another product = %d, context version %v", rec.Code, seen)
+	}
+}
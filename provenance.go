@@ -0,0 +1,96 @@
+package version
+
+import "strings"
+
+// Source records where a field value came from.
+type Source string
+
+// Sources a field can be resolved from. Values loaded from a file use
+// FileSource, which records the path.
+const (
+	SourceLdflags   Source = "ldflags"
+	SourceBuildInfo Source = "buildinfo"
+	SourceGit       Source = "git"
+	SourceEnv       Source = "env"
+	SourceSetter    Source = "setter"
+)
+
+// filePrefix prefixes the path of file sources.
+const filePrefix = "file:"
+
+// FileSource returns the source for values loaded from the file at path.
+func FileSource(path string) Source {
+	return Source(filePrefix + path)
+}
+
+// IsFile reports whether the value was loaded from a file.
+func (s Source) IsFile() bool {
+	return strings.HasPrefix(string(s), filePrefix)
+}
+
+// Path returns the file path of a file source, or "" for other sources.
+func (s Source) Path() string {
+	if !s.IsFile() {
+		return ""
+	}
+	return strings.TrimPrefix(string(s), filePrefix)
+}
+
+// Field names a piece of metadata tracked by Provenance.
+type Field string
+
+// Tracked fields.
+const (
+	FieldAppName        Field = "app.name"
+	FieldAppDescription Field = "app.description"
+	FieldChangelog      Field = "app.changelog"
+	FieldVersion        Field = "version"
+	FieldTimestamp      Field = "build.timestamp"
+	FieldCommit         Field = "git.commit"
+	FieldBranch         Field = "git.branch"
+	FieldRepo           Field = "git.repo"
+	FieldDirty          Field = "git.dirty"
+	// FieldExtra sets the precedence of all extra labels, see ExtraField for
+	// their provenance.
+	FieldExtra Field = "extra"
+)
+
+// ExtraField returns the field under which the source of an extra label is
+// recorded, e.g. "extra.pipeline".
+func ExtraField(key string) Field {
+	return Field(string(FieldExtra) + "." + key)
+}
+
+// fieldOrder is the order fields are listed in PrintVerbose.
+var fieldOrder = []Field{
+	FieldAppName,
+	FieldAppDescription,
+	FieldChangelog,
+	FieldVersion,
+	FieldTimestamp,
+	FieldCommit,
+	FieldBranch,
+	FieldRepo,
+	FieldDirty,
+}
+
+// Sources maps each field that has a value to the source it came from.
+type Sources map[Field]Source
+
+// clone returns a copy that can be modified without affecting p.
+func (p Sources) clone() Sources {
+	c := make(Sources, len(p))
+	for k, v := range p {
+		c[k] = v
+	}
+	return c
+}
+
+// record sets the source of a field, or removes it when the value is empty.
+func (p Sources) record(field Field, src Source, value string) {
+	if value == "" {
+		delete(p, field)
+		return
+	}
+	p[field] = src
+}
@@ -0,0 +1,121 @@
+package version
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSource_File(t *testing.T) {
+	src := FileSource("/etc/app/.version")
+	if src != "file:/etc/app/.version" || !src.IsFile() || src.Path() != "/etc/app/.version" {
+		t.Errorf("FileSource() = %q, IsFile %v, Path %q", src, src.IsFile(), src.Path())
+	}
+	if SourceLdflags.IsFile() || SourceLdflags.Path() != "" {
+		t.Errorf("ldflags source should not be a file source")
+	}
+}
+
+func TestProvenance_Setters(t *testing.T) {
+	i := New(WithAppInfo("app", ""), WithVersion("1.2.3"), WithGitInfo("abc", "main", ""))
+
+	want := Sources{
+		FieldAppName: SourceSetter,
+		FieldVersion: SourceSetter,
+		FieldCommit:  SourceSetter,
+		FieldBranch:  SourceSetter,
+	}
+	got := i.Provenance()
+	if len(got) != len(want) {
+		t.Fatalf("Provenance() = %v, want %v", got, want)
+	}
+	for f, src := range want {
+		if got[f] != src {
+			t.Errorf("Provenance()[%s] = %q, want %q", f, got[f], src)
+		}
+	}
+}
+
+func TestProvenance_Ldflags(t *testing.T) {
+	old := [...]string{VersionInfo, GitCommit, GitBranch, GitRepo, BuildTimestamp}
+	defer func() {
+		VersionInfo, GitCommit, GitBranch, GitRepo, BuildTimestamp = old[0], old[1], old[2], old[3], old[4]
+	}()
+	VersionInfo, GitCommit, GitBranch, GitRepo, BuildTimestamp = "v3.2.1", "ldcommit", "", "", "2024-02-03"
+
+	i := New(WithLdflags(), WithRuntimeBuildInfo())
+	p := i.Provenance()
+	for _, f := range []Field{FieldVersion, FieldCommit, FieldTimestamp} {
+		if p[f] != SourceLdflags {
+			t.Errorf("Provenance()[%s] = %q, want ldflags", f, p[f])
+		}
+	}
+}
+
+func TestProvenance_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".version")
+	if err := os.WriteFile(path, []byte("VERSION=1.0.0\nGIT_COMMIT=filecommit\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	i := New(WithGitInfo("setcommit", "", ""))
+	if err := i.LoadFromFile(path); err != nil {
+		t.Fatal(err)
+	}
+	p := i.Provenance()
+	if p[FieldVersion] != FileSource(path) {
+		t.Errorf("version source = %q, want %q", p[FieldVersion], FileSource(path))
+	}
+	if p[FieldCommit] != SourceSetter {
+		t.Errorf("commit kept from setter should keep its source, got %q", p[FieldCommit])
+	}
+}
+
+func TestProvenance_Copy(t *testing.T) {
+	i := New(WithVersion("1.0.0"))
+	p := i.Provenance()
+	p[FieldVersion] = SourceEnv
+	delete(p, FieldVersion)
+	if i.Provenance()[FieldVersion] != SourceSetter {
+		t.Error("modifying the returned map should not affect the Info")
+	}
+}
+
+func TestProvenance_Cleared(t *testing.T) {
+	i := New(WithVersion("1.0.0"))
+	_ = i.SetVersion("")
+	if _, ok := i.Provenance()[FieldVersion]; ok {
+		t.Error("clearing the version should clear its source")
+	}
+}
+
+func TestPrintVerbose(t *testing.T) {
+	resetState()
+	SetAppInfo("testapp", "")
+	SetVersion("1.2.3")
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	PrintVerbose()
+
+	w.Close()
+	os.Stdout = old
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	output := buf.String()
+
+	for _, want := range []string{"Running:", "Sources:", "app.name", "version          setter"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("PrintVerbose() output should contain %q, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "git.commit") {
+		t.Errorf("PrintVerbose() should omit fields without a value, got %q", output)
+	}
+}
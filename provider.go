@@ -0,0 +1,491 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/rbaliyan/go-version/internal/gitrepo"
+)
+
+// Partial holds the fields a Provider found. Empty fields are not set.
+type Partial struct {
+	// source recorded in Provenance for every field, SourceCustom if empty
+	Source Source
+
+	AppName        string
+	AppDescription string
+	Changelog      string
+	Version        string
+	// build timestamp in one of the formats accepted by SetBuildInfo
+	Timestamp string
+	Commit    string
+	Branch    string
+	Repo      string
+	// "true" or "false" if known whether the working tree was modified
+	Dirty string
+	// extra labels, merged key by key
+	Extra map[string]string
+}
+
+// SourceCustom is recorded for fields from a Provider that leaves
+// Partial.Source empty.
+const SourceCustom Source = "custom"
+
+// get returns the value of a field.
+func (p Partial) get(f Field) string {
+	switch f {
+	case FieldAppName:
+		return p.AppName
+	case FieldAppDescription:
+		return p.AppDescription
+	case FieldChangelog:
+		return p.Changelog
+	case FieldVersion:
+		return p.Version
+	case FieldTimestamp:
+		return p.Timestamp
+	case FieldCommit:
+		return p.Commit
+	case FieldBranch:
+		return p.Branch
+	case FieldRepo:
+		return p.Repo
+	case FieldDirty:
+		return p.Dirty
+	}
+	return ""
+}
+
+// Provider loads version metadata from one source, e.g. ldflags, a file or
+// a secrets store. Providers are combined with Resolve.
+type Provider interface {
+	Load(ctx context.Context) (Partial, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface.
+type ProviderFunc func(ctx context.Context) (Partial, error)
+
+// Load calls f(ctx).
+func (f ProviderFunc) Load(ctx context.Context) (Partial, error) {
+	return f(ctx)
+}
+
+// ErrUnavailable is returned (possibly wrapped) by a Provider whose source
+// does not exist, e.g. a missing file or a directory that is not a git
+// repository. Resolve skips such providers.
+var ErrUnavailable = errors.New("version source unavailable")
+
+// unavailableError marks err as ErrUnavailable while keeping it unwrappable,
+// so os.IsNotExist style checks on the underlying error still work.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *unavailableError) Unwrap() error {
+	return e.err
+}
+
+// Is allows errors.Is(err, ErrUnavailable).
+func (e *unavailableError) Is(target error) bool {
+	return target == ErrUnavailable
+}
+
+// Precedence decides whether a later provider may overwrite a field.
+type Precedence int
+
+const (
+	// FirstWins keeps the first non-empty value. This is the default and
+	// matches the setters: values that are already set are never overwritten.
+	FirstWins Precedence = iota
+	// LastWins lets every later non-empty value overwrite the field.
+	LastWins
+)
+
+// Resolve loads every provider in order and merges the results into the
+// Info. The current values count as coming before the first provider, so
+// with the default FirstWins precedence providers only fill empty fields.
+// Use SetPrecedence to let later providers overwrite a field.
+//
+// Providers that return ErrUnavailable are skipped. Any other error is
+// returned and nothing is changed. Invalid values are handled as by the
+// setters: an invalid version is kept in Version.Raw only, unparseable
+// timestamps are ignored, and a changelog that fails to parse is kept as text
+// without App().Changes and its error is returned after merging.
+func (i *Info) Resolve(providers ...Provider) error {
+	return i.ResolveContext(context.Background(), providers...)
+}
+
+// ResolveContext is like Resolve but passes ctx to the providers.
+func (i *Info) ResolveContext(ctx context.Context, providers ...Provider) error {
+	// Load outside the lock, providers may run commands or do I/O
+	partials := make([]Partial, 0, len(providers))
+	for _, p := range providers {
+		part, err := p.Load(ctx)
+		if errors.Is(err, ErrUnavailable) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		partials = append(partials, part)
+	}
+
+	var err error
+	i.update(func(s *state) {
+		for _, part := range partials {
+			if mergeErr := s.merge(part); err == nil {
+				err = mergeErr
+			}
+		}
+	})
+	return err
+}
+
+// loadOne resolves a single provider, returning its error even if it is
+// ErrUnavailable. The LoadFrom functions use it to keep reporting missing
+// files and repositories.
+func (i *Info) loadOne(p Provider) error {
+	part, err := p.Load(context.Background())
+	var u *unavailableError
+	if errors.As(err, &u) {
+		return u.err
+	}
+	if err != nil {
+		return err
+	}
+	i.update(func(s *state) { err = s.merge(part) })
+	return err
+}
+
+// SetPrecedence sets how Resolve merges a field, see Precedence.
+func (i *Info) SetPrecedence(f Field, p Precedence) {
+	i.update(func(s *state) {
+		prec := make(map[Field]Precedence, len(s.prec)+1)
+		for k, v := range s.prec {
+			prec[k] = v
+		}
+		prec[f] = p
+		s.prec = prec
+	})
+}
+
+// WithPrecedence sets how Resolve merges a field, see SetPrecedence.
+func WithPrecedence(f Field, p Precedence) Option {
+	return func(i *Info) { i.SetPrecedence(f, p) }
+}
+
+// LdflagsProvider returns a Provider for the variables injected with
+// -ldflags -X (VersionInfo, GitCommit, GitBranch, GitRepo, GitDirty,
+// BuildTimestamp, ExtraInfo).
+func LdflagsProvider() Provider {
+	return ProviderFunc(func(context.Context) (Partial, error) {
+		return LdflagsPartial(map[string]string{
+			"VersionInfo":    VersionInfo,
+			"BuildTimestamp": BuildTimestamp,
+			"GitCommit":      GitCommit,
+			"GitBranch":      GitBranch,
+			"GitRepo":        GitRepo,
+			"GitDirty":       GitDirty,
+			"ExtraInfo":      ExtraInfo,
+		}), nil
+	})
+}
+
+// LdflagsPartial returns the Partial for values of the variables injected
+// with -ldflags -X, keyed by variable name, e.g. as read from the -ldflags
+// build setting of another binary. Unknown names are ignored.
+func LdflagsPartial(vars map[string]string) Partial {
+	return Partial{
+		Source:    SourceLdflags,
+		Version:   vars["VersionInfo"],
+		Timestamp: vars["BuildTimestamp"],
+		Commit:    vars["GitCommit"],
+		Branch:    vars["GitBranch"],
+		Repo:      vars["GitRepo"],
+		Dirty:     vars["GitDirty"],
+		Extra:     parseExtraInfo(vars["ExtraInfo"]),
+	}
+}
+
+// EncodeExtraInfo encodes labels for the ExtraInfo variable, e.g.
+// "channel=beta&pipeline=1234".
+func EncodeExtraInfo(extra map[string]string) string {
+	values := url.Values{}
+	for k, v := range extra {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// parseExtraInfo decodes URL-encoded labels such as "k=v&k2=v2". The first
+// value of a key wins and malformed pairs are skipped.
+func parseExtraInfo(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	values, _ := url.ParseQuery(s)
+	extra := make(map[string]string, len(values))
+	for k, v := range values {
+		if k != "" {
+			extra[k] = v[0]
+		}
+	}
+	return extra
+}
+
+// BuildInfoProvider returns a Provider for the module version and VCS
+// settings recorded by the go command, see debug.ReadBuildInfo. This provides
+// correct version info for binaries built with go install.
+func BuildInfoProvider() Provider {
+	return ProviderFunc(func(context.Context) (Partial, error) {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return Partial{}, ErrUnavailable
+		}
+		p := Partial{Source: SourceBuildInfo}
+		if info.Main.Version != "(devel)" {
+			p.Version = info.Main.Version
+		}
+		// VCS settings are available in Go 1.18+
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				p.Commit = s.Value
+			case "vcs.time":
+				p.Timestamp = s.Value
+			case "vcs.modified":
+				p.Dirty = s.Value
+			}
+		}
+		return p, nil
+	})
+}
+
+// FileProvider returns a Provider for a version file. The format is
+// detected from the extension, see FormatOf and File for the schema. In the
+// default key=value format blank lines, lines starting with '#' and unknown
+// keys are ignored and the first occurrence of a key wins. A missing file is
+// reported as ErrUnavailable.
+func FileProvider(path string) Provider {
+	return ProviderFunc(func(context.Context) (Partial, error) {
+		data, err := os.ReadFile(path) // #nosec G304 -- reading user-specified version file
+		if os.IsNotExist(err) {
+			return Partial{}, &unavailableError{err}
+		}
+		if err != nil {
+			return Partial{}, err
+		}
+		return parseVersionFile(data, FormatOf(path), FileSource(path))
+	})
+}
+
+// FSProvider returns a Provider for the file name in fsys, e.g. an embed.FS,
+// like FileProvider. A missing file is reported as ErrUnavailable.
+func FSProvider(fsys fs.FS, name string) Provider {
+	return ProviderFunc(func(context.Context) (Partial, error) {
+		data, err := fs.ReadFile(fsys, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			return Partial{}, &unavailableError{err}
+		}
+		if err != nil {
+			return Partial{}, err
+		}
+		return parseVersionFile(data, FormatOf(name), FileSource(name))
+	})
+}
+
+// SourceBytes is recorded for fields loaded with LoadFromBytes.
+const SourceBytes Source = "bytes"
+
+// BytesProvider returns a Provider for version file content in the given
+// format.
+func BytesProvider(data []byte, format Format) Provider {
+	return ProviderFunc(func(context.Context) (Partial, error) {
+		return parseVersionFile(data, format, SourceBytes)
+	})
+}
+
+// parseVersionFile parses version file content.
+func parseVersionFile(data []byte, format Format, src Source) (Partial, error) {
+	f, err := ParseFile(data, format)
+	if err != nil {
+		return Partial{}, err
+	}
+	return f.partial(src), nil
+}
+
+// GitProvider returns a Provider for the git repository containing the
+// working directory: the HEAD commit and branch, the version as git describe
+// --tags --always would print it, the repository from the origin remote URL
+// and whether tracked files have uncommitted changes.
+//
+// The repository is read directly, without the git binary. git is only run
+// to detect uncommitted changes, and in place of the reader for repository
+// formats it does not support. Outside a repository it reports ErrUnavailable.
+func GitProvider() Provider {
+	return ProviderFunc(func(ctx context.Context) (Partial, error) {
+		p, err := readGitRepo(".")
+		switch {
+		case errors.Is(err, gitrepo.ErrNotRepository):
+			return Partial{}, &unavailableError{err}
+		case err != nil:
+			return gitBinary(ctx)
+		}
+		if _, err := exec.LookPath("git"); err == nil {
+			p.Dirty = strconv.FormatBool(gitOutput(ctx, "status", "--porcelain", "--untracked-files=no") != "")
+		}
+		return p, nil
+	})
+}
+
+// readGitRepo reads the repository containing dir with the built-in reader.
+func readGitRepo(dir string) (Partial, error) {
+	r, err := gitrepo.Open(dir)
+	if err != nil {
+		return Partial{}, err
+	}
+	defer r.Close()
+	branch, commit, err := r.Head()
+	if err != nil {
+		return Partial{}, err
+	}
+	if branch == "" {
+		// detached, as printed by git rev-parse --abbrev-ref HEAD
+		branch = "HEAD"
+	}
+	p := Partial{Source: SourceGit, Commit: commit, Branch: branch, Repo: r.RemoteURL("origin")}
+	if commit != "" {
+		if p.Version, err = r.Describe(commit); err != nil {
+			return Partial{}, err
+		}
+	}
+	return p, nil
+}
+
+// gitBinary runs git for the fields read by GitProvider.
+func gitBinary(ctx context.Context) (Partial, error) {
+	if err := exec.CommandContext(ctx, "git", "rev-parse", "--git-dir").Run(); err != nil {
+		return Partial{}, &unavailableError{err}
+	}
+	return Partial{
+		Source:  SourceGit,
+		Commit:  gitOutput(ctx, "rev-parse", "HEAD"),
+		Branch:  gitOutput(ctx, "rev-parse", "--abbrev-ref", "HEAD"),
+		Version: gitOutput(ctx, "describe", "--tags", "--always"),
+		Repo:    gitOutput(ctx, "remote", "get-url", "origin"),
+		Dirty:   strconv.FormatBool(gitOutput(ctx, "status", "--porcelain", "--untracked-files=no") != ""),
+	}, nil
+}
+
+// gitOutput runs a git command and returns its trimmed output, or "" on error.
+func gitOutput(ctx context.Context, args ...string) string {
+	out, err := exec.CommandContext(ctx, "git", args...).Output() // #nosec G204 -- all callers pass hardcoded git subcommands
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// merge applies the non-empty fields of p according to the precedence of
+// each field. It returns the changelog parse error, if any.
+func (s *state) merge(p Partial) error {
+	src := p.Source
+	if src == "" {
+		src = SourceCustom
+	}
+	var err error
+	for _, f := range fieldOrder {
+		value := p.get(f)
+		if value == "" || (s.prec[f] == FirstWins && s.has(f)) {
+			continue
+		}
+		if setErr := s.set(f, value, src); err == nil {
+			err = setErr
+		}
+	}
+	for _, key := range sortedKeys(p.Extra) {
+		if _, ok := s.build.Extra[key]; ok && s.prec[FieldExtra] == FirstWins {
+			continue
+		}
+		s.setExtra(key, p.Extra[key], src)
+	}
+	return err
+}
+
+// has reports whether a field has a value.
+func (s *state) has(f Field) bool {
+	switch f {
+	case FieldAppName:
+		return s.app.Name != ""
+	case FieldAppDescription:
+		return s.app.Description != ""
+	case FieldChangelog:
+		return s.app.Changelog != ""
+	case FieldVersion:
+		return s.version.Raw != ""
+	case FieldTimestamp:
+		return !s.build.Timestamp.IsZero()
+	case FieldCommit:
+		return s.build.Git.Commit != ""
+	case FieldBranch:
+		return s.build.Git.Branch != ""
+	case FieldRepo:
+		return s.build.Git.Repo != ""
+	case FieldDirty:
+		_, ok := s.prov[FieldDirty]
+		return ok
+	}
+	return false
+}
+
+// set sets a single field, overwriting any current value. Only a changelog
+// parse error is returned; other invalid values are handled as by the
+// setters.
+func (s *state) set(f Field, value string, src Source) error {
+	switch f {
+	case FieldAppName:
+		s.app.Name = value
+		s.prov.record(f, src, value)
+	case FieldAppDescription:
+		s.app.Description = value
+		s.prov.record(f, src, value)
+	case FieldChangelog:
+		s.app.Changelog = value
+		s.prov.record(f, src, value)
+		// on failure Changes is cleared, it must not describe an older text
+		parsed, err := ParseChangelog(value)
+		s.app.Changes = parsed
+		return err
+	case FieldVersion:
+		_ = s.setVersion(value, src)
+	case FieldTimestamp:
+		if t, ok := parseTimestamp(value); ok {
+			s.build.Timestamp = t
+			s.prov.record(f, src, value)
+		}
+	case FieldCommit:
+		s.setCommit(value, src)
+	case FieldBranch:
+		s.setBranch(value, src)
+	case FieldRepo:
+		s.setRepo(value, src)
+	case FieldDirty:
+		if dirty, err := strconv.ParseBool(value); err == nil {
+			s.build.Git.Dirty = dirty
+			s.prov.record(f, src, value)
+		}
+	}
+	return nil
+}
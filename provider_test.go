@@ -0,0 +1,406 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func staticProvider(p Partial) Provider {
+	return ProviderFunc(func(context.Context) (Partial, error) { return p, nil })
+}
+
+func TestResolve_FirstWins(t *testing.T) {
+	i := New(WithGitInfo("setcommit", "", ""))
+	err := i.Resolve(
+		staticProvider(Partial{Source: "one", Version: "1.0.0", Commit: "c1"}),
+		staticProvider(Partial{Source: "two", Version: "2.0.0", Branch: "main"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Get().Raw != "1.0.0" || i.Git().Commit != "setcommit" || i.Git().Branch != "main" {
+		t.Errorf("Resolve() = %+v %+v", i.Get(), i.Git())
+	}
+	p := i.Provenance()
+	if p[FieldVersion] != "one" || p[FieldCommit] != SourceSetter || p[FieldBranch] != "two" {
+		t.Errorf("Provenance() = %v", p)
+	}
+}
+
+func TestResolve_LastWins(t *testing.T) {
+	i := New(WithVersion("0.1.0"), WithPrecedence(FieldVersion, LastWins))
+	err := i.Resolve(
+		staticProvider(Partial{Version: "1.0.0", Commit: "c1"}),
+		staticProvider(Partial{Version: "2.0.0", Commit: "c2"}),
+		staticProvider(Partial{Commit: "c3"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Get().Raw != "2.0.0" {
+		t.Errorf("version with LastWins = %q, want 2.0.0", i.Get().Raw)
+	}
+	if i.Git().Commit != "c1" {
+		t.Errorf("commit with FirstWins = %q, want c1", i.Git().Commit)
+	}
+	if i.Provenance()[FieldVersion] != SourceCustom {
+		t.Errorf("empty Partial.Source should be recorded as %q, got %q", SourceCustom, i.Provenance()[FieldVersion])
+	}
+}
+
+func TestResolve_SkipsUnavailable(t *testing.T) {
+	i := New()
+	err := i.Resolve(
+		FileProvider(filepath.Join(t.TempDir(), "missing")),
+		ProviderFunc(func(context.Context) (Partial, error) { return Partial{}, ErrUnavailable }),
+		staticProvider(Partial{Version: "1.0.0"}),
+	)
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if i.Get().Raw != "1.0.0" {
+		t.Errorf("Get() = %q", i.Get().Raw)
+	}
+}
+
+func TestResolve_ErrorChangesNothing(t *testing.T) {
+	boom := errors.New("boom")
+	i := New()
+	err := i.Resolve(
+		staticProvider(Partial{Version: "1.0.0"}),
+		ProviderFunc(func(context.Context) (Partial, error) { return Partial{}, boom }),
+	)
+	if !errors.Is(err, boom) {
+		t.Fatalf("Resolve() error = %v, want %v", err, boom)
+	}
+	if i.Get().Raw != "" {
+		t.Errorf("failed Resolve should not change the Info, got %q", i.Get().Raw)
+	}
+}
+
+func TestResolve_InvalidValues(t *testing.T) {
+	i := New()
+	err := i.Resolve(staticProvider(Partial{Version: "not-a-version", Timestamp: "yesterday", Changelog: "## [1.0.0]\n- x\n"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := i.Get(); v.Raw != "not-a-version" || v.Major != 0 {
+		t.Errorf("invalid version should be kept in Raw, got %+v", v)
+	}
+	if !i.Build().Timestamp.IsZero() {
+		t.Errorf("invalid timestamp should be ignored, got %v", i.Build().Timestamp)
+	}
+	if _, ok := i.Provenance()[FieldTimestamp]; ok {
+		t.Error("ignored timestamp should have no source")
+	}
+	if len(i.App().Changes.Releases) != 1 {
+		t.Errorf("changelog should be parsed, got %+v", i.App().Changes)
+	}
+}
+
+func TestResolve_ChangelogError(t *testing.T) {
+	i := New()
+	i.SetPrecedence(FieldChangelog, LastWins)
+	err := i.Resolve(
+		staticProvider(Partial{Changelog: "## [1.0.0]\n- x\n"}),
+		staticProvider(Partial{Version: "1.2.3", Changelog: "## [1.0] - 2024-01-01\n- y\n"}),
+	)
+	if !errors.Is(err, ErrInvalidChangelog) {
+		t.Fatalf("Resolve() error = %v, want ErrInvalidChangelog", err)
+	}
+	if !strings.Contains(i.App().Changelog, "1.0]") || len(i.App().Changes.Releases) != 0 {
+		t.Errorf("App() = %+v, want the raw text kept and Changes cleared", i.App())
+	}
+	if i.Get().Raw != "1.2.3" {
+		t.Errorf("other fields should be merged, got version %q", i.Get().Raw)
+	}
+}
+
+func TestResolve_Context(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "1.2.3")
+	i := New()
+	err := i.ResolveContext(ctx, ProviderFunc(func(ctx context.Context) (Partial, error) {
+		return Partial{Version: ctx.Value(key{}).(string)}, nil
+	}))
+	if err != nil || i.Get().Raw != "1.2.3" {
+		t.Errorf("ResolveContext() = %q, %v", i.Get().Raw, err)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".version")
+	content := "# comment\nVERSION = 1.2.3\nVERSION=9.9.9\nGIT_COMMIT=abc\nUNKNOWN=x\nnot a pair\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := FileProvider(path).Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Partial{Source: FileSource(path), Version: "1.2.3", Commit: "abc", Extra: map[string]string{"UNKNOWN": "x"}}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("Load() = %+v, want %+v", p, want)
+	}
+}
+
+func TestFileProvider_Missing(t *testing.T) {
+	_, err := FileProvider(filepath.Join(t.TempDir(), "missing")).Load(context.Background())
+	if !errors.Is(err, ErrUnavailable) || !os.IsNotExist(errors.Unwrap(err)) {
+		t.Errorf("Load() error = %v, want ErrUnavailable wrapping a not-exist error", err)
+	}
+}
+
+func TestLdflagsProvider(t *testing.T) {
+	old := [...]string{VersionInfo, GitCommit, GitBranch, GitRepo, GitDirty, BuildTimestamp}
+	defer func() {
+		VersionInfo, GitCommit, GitBranch, GitRepo, GitDirty, BuildTimestamp = old[0], old[1], old[2], old[3], old[4], old[5]
+	}()
+	VersionInfo, GitCommit, GitBranch, GitRepo, GitDirty, BuildTimestamp = "v1.0.0", "c", "b", "r", "true", "2024-01-02"
+
+	p, err := LdflagsProvider().Load(context.Background())
+	want := Partial{Source: SourceLdflags, Version: "v1.0.0", Commit: "c", Branch: "b", Repo: "r", Dirty: "true", Timestamp: "2024-01-02"}
+	if err != nil || !reflect.DeepEqual(p, want) {
+		t.Errorf("Load() = %+v, %v, want %+v", p, err, want)
+	}
+}
+
+func TestResolve_Dirty(t *testing.T) {
+	i := New()
+	err := i.Resolve(
+		staticProvider(Partial{Source: "a", Dirty: "false"}),
+		staticProvider(Partial{Source: "b", Dirty: "true"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Git().Dirty {
+		t.Error("first provider should win, want a clean tree")
+	}
+	if src := i.Provenance()[FieldDirty]; src != "a" {
+		t.Errorf("Provenance()[%s] = %q, want %q", FieldDirty, src, "a")
+	}
+
+	i = New()
+	if err := i.Resolve(staticProvider(Partial{Dirty: "maybe"})); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := i.Provenance()[FieldDirty]; ok || i.Git().Dirty {
+		t.Error("invalid dirty flag should be ignored")
+	}
+
+	i = New()
+	if err := i.LoadFromBytes([]byte("GIT_COMMIT=abc\nGIT_DIRTY=true\n")); err != nil {
+		t.Fatal(err)
+	}
+	if !i.Git().Dirty {
+		t.Error("GIT_DIRTY=true should mark the tree dirty")
+	}
+}
+
+// gitFixture creates a repository with a tag, a later commit and an origin
+// remote, and changes to it for the rest of the test.
+func gitFixture(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("skipping: git not installed")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"symbolic-ref", "HEAD", "refs/heads/main"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "one"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "tag", "-a", "-m", "release", "v1.0.0"},
+		{"-c", "user.name=Test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "two"},
+		{"remote", "add", "origin", "git@github.com:org/app.git"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+func TestGitProvider_MatchesGit(t *testing.T) {
+	gitFixture(t)
+	ctx := context.Background()
+	want, err := gitBinary(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := GitProvider().Load(ctx)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("Load() = %+v, %v, want %+v", got, err, want)
+	}
+	if !strings.HasPrefix(got.Version, "v1.0.0-1-g") || got.Dirty != "false" {
+		t.Errorf("Load() = %+v", got)
+	}
+
+	// detached HEAD
+	if out, err := exec.Command("git", "checkout", "-q", "v1.0.0").CombinedOutput(); err != nil {
+		t.Fatalf("git checkout: %v\n%s", err, out)
+	}
+	want, _ = gitBinary(ctx)
+	if got, err := GitProvider().Load(ctx); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("detached Load() = %+v, %v, want %+v", got, err, want)
+	}
+}
+
+func TestGitProvider_WithoutBinary(t *testing.T) {
+	dir := gitFixture(t)
+	commit, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", "")
+
+	p, err := GitProvider().Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Commit != strings.TrimSpace(string(commit)) || p.Branch != "main" || p.Repo != "git@github.com:org/app.git" {
+		t.Errorf("Load() = %+v", p)
+	}
+	// uncommitted changes cannot be detected without git
+	if p.Dirty != "" {
+		t.Errorf("Dirty = %q, want unknown", p.Dirty)
+	}
+
+	if err := os.Chdir(filepath.Dir(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GitProvider().Load(context.Background()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Load() outside a repository error = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestLoadFromFile_MissingFileIsNotExist(t *testing.T) {
+	err := New().LoadFromFile(filepath.Join(t.TempDir(), "missing"))
+	if !os.IsNotExist(err) {
+		t.Errorf("LoadFromFile() error = %v, want a not-exist error", err)
+	}
+}
+
+func TestLoadFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"build/.version": {Data: []byte("VERSION=1.2.3\nGIT_COMMIT=embedded\n")},
+	}
+	i := New()
+	if err := i.LoadFromFS(fsys, "build/.version"); err != nil {
+		t.Fatal(err)
+	}
+	if i.Get().Raw != "1.2.3" || i.Git().Commit != "embedded" {
+		t.Errorf("LoadFromFS() = %q %q", i.Get().Raw, i.Git().Commit)
+	}
+	if src := i.Provenance()[FieldVersion]; src != FileSource("build/.version") {
+		t.Errorf("version source = %q", src)
+	}
+
+	err := New().LoadFromFS(fsys, "missing")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadFromFS(missing) error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestLoadFromBytes(t *testing.T) {
+	i := New(WithGitInfo("set", "", ""))
+	if err := i.LoadFromBytes([]byte("# embedded\nVERSION = 2.0.0\nGIT_COMMIT=bytes\n")); err != nil {
+		t.Fatal(err)
+	}
+	if i.Get().Raw != "2.0.0" || i.Git().Commit != "set" {
+		t.Errorf("LoadFromBytes() = %q %q", i.Get().Raw, i.Git().Commit)
+	}
+	if src := i.Provenance()[FieldVersion]; src != SourceBytes {
+		t.Errorf("version source = %q, want %q", src, SourceBytes)
+	}
+}
+
+func TestFileFormats_SameParser(t *testing.T) {
+	content := []byte("VERSION=1.0.0\nVERSION=2.0.0\nGIT_BRANCH = main\n# GIT_REPO=x\nBUILD_TIMESTAMP=2024-01-02\n")
+	path := filepath.Join(t.TempDir(), ".version")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	providers := []Provider{
+		FileProvider(path),
+		FSProvider(fstest.MapFS{".version": {Data: content}}, ".version"),
+		BytesProvider(content, FormatEnv),
+	}
+	for _, p := range providers {
+		got, err := p.Load(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got.Source = ""
+		want := Partial{Version: "1.0.0", Branch: "main", Timestamp: "2024-01-02"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Load() = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestLdflags_ExtraInfo(t *testing.T) {
+	old := ExtraInfo
+	defer func() { ExtraInfo = old }()
+	ExtraInfo = "pipeline=1234&host=builder%201&channel=beta&channel=stable&bad%zz"
+
+	i := New(WithLdflags())
+	extra := i.Build().Extra
+	if extra["pipeline"] != "1234" || extra["host"] != "builder 1" || extra["channel"] != "beta" {
+		t.Errorf("Extra = %v", extra)
+	}
+	if src := i.Provenance()[ExtraField("pipeline")]; src != SourceLdflags {
+		t.Errorf("extra.pipeline source = %q, want ldflags", src)
+	}
+}
+
+func TestLdflagsPartial(t *testing.T) {
+	extra := map[string]string{"host": "builder 1", "note": "a=b&c", "channel": "beta"}
+	got := LdflagsPartial(map[string]string{
+		"VersionInfo": "v1.2.3",
+		"GitDirty":    "false",
+		"ExtraInfo":   EncodeExtraInfo(extra),
+		"Other":       "x",
+	})
+	want := Partial{Source: SourceLdflags, Version: "v1.2.3", Dirty: "false", Extra: extra}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LdflagsPartial() = %+v, want %+v", got, want)
+	}
+}
+
+func TestResolve_ExtraPrecedence(t *testing.T) {
+	i := New(WithPrecedence(FieldExtra, LastWins))
+	i.SetExtra("channel", "beta")
+	err := i.Resolve(staticProvider(Partial{Source: "file", Extra: map[string]string{"channel": "stable", "ticket": "OPS-1"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extra := i.Build().Extra; extra["channel"] != "stable" || extra["ticket"] != "OPS-1" {
+		t.Errorf("Extra = %v", extra)
+	}
+
+	i = New()
+	i.SetExtra("channel", "beta")
+	_ = i.Resolve(staticProvider(Partial{Extra: map[string]string{"channel": "stable"}}))
+	if i.Build().Extra["channel"] != "beta" {
+		t.Errorf("FirstWins should keep the existing label, got %v", i.Build().Extra)
+	}
+}
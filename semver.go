@@ -0,0 +1,191 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Mode controls how strictly version strings are validated by ParseWithMode.
+type Mode int
+
+const (
+	// Strict accepts exactly the SemVer 2.0.0 grammar. The only extension is an
+	// optional leading "v", as used by Go module and git tags.
+	Strict Mode = iota
+	// Lenient additionally accepts surrounding whitespace, missing minor and
+	// patch components ("1", "1.2") and leading zeros in numeric components.
+	Lenient
+)
+
+// ErrInvalidVersion is returned (wrapped in a *ParseError) when a string is
+// not a valid semantic version.
+var ErrInvalidVersion = errors.New("invalid version")
+
+// ParseError describes why a version string could not be parsed.
+type ParseError struct {
+	// input string as passed to Parse
+	Input string
+	// human readable reason
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid version %q: %s", e.Input, e.Reason)
+}
+
+// Unwrap allows errors.Is(err, ErrInvalidVersion).
+func (e *ParseError) Unwrap() error {
+	return ErrInvalidVersion
+}
+
+// Identifier is a single dot-separated prerelease identifier such as "rc" or "1".
+type Identifier string
+
+// IsNumeric reports whether the identifier consists only of ASCII digits.
+func (id Identifier) IsNumeric() bool {
+	return id != "" && isDigits(string(id))
+}
+
+// Parse parses s as a SemVer 2.0.0 version in Strict mode.
+// An optional leading "v" is accepted and preserved in Version.Raw.
+func Parse(s string) (Version, error) {
+	return ParseWithMode(s, Strict)
+}
+
+// MustParse is like Parse but panics if s is not a valid version.
+// It is intended for constants and tests.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// ParseWithMode parses s as a semantic version using the given mode.
+// On error the returned Version is the zero value.
+func ParseWithMode(s string, mode Mode) (Version, error) {
+	str := s
+	if mode == Lenient {
+		str = strings.TrimSpace(str)
+	}
+	str = strings.TrimPrefix(str, "v")
+	if str == "" {
+		return Version{}, invalid(s, "empty version")
+	}
+
+	ver := Version{Raw: s}
+
+	// Build metadata is everything after the first '+'
+	core := str
+	if i := strings.IndexByte(core, '+'); i >= 0 {
+		build, err := parseBuild(s, core[i+1:])
+		if err != nil {
+			return Version{}, err
+		}
+		ver.Build = build
+		core = core[:i]
+	}
+
+	// Prerelease is everything after the first '-' of the remainder
+	if i := strings.IndexByte(core, '-'); i >= 0 {
+		pre, err := parsePrerelease(s, core[i+1:], mode)
+		if err != nil {
+			return Version{}, err
+		}
+		ver.Prerelease = pre
+		ver.Prefix = core[i+1:]
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 || (len(parts) < 3 && mode != Lenient) {
+		return Version{}, invalid(s, "expected MAJOR.MINOR.PATCH")
+	}
+	var nums [3]int
+	for i, p := range parts {
+		n, err := parseNumber(s, p, mode)
+		if err != nil {
+			return Version{}, err
+		}
+		nums[i] = n
+	}
+	ver.Major, ver.Minor, ver.Patch = nums[0], nums[1], nums[2]
+	return ver, nil
+}
+
+func parseNumber(input, s string, mode Mode) (int, error) {
+	if s == "" {
+		return 0, invalid(input, "empty numeric component")
+	}
+	if !isDigits(s) {
+		return 0, invalid(input, fmt.Sprintf("non-numeric component %q", s))
+	}
+	if mode == Strict && len(s) > 1 && s[0] == '0' {
+		return 0, invalid(input, fmt.Sprintf("leading zero in %q", s))
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, invalid(input, fmt.Sprintf("component %q out of range", s))
+	}
+	return n, nil
+}
+
+func parsePrerelease(input, s string, mode Mode) ([]Identifier, error) {
+	parts := strings.Split(s, ".")
+	ids := make([]Identifier, 0, len(parts))
+	for _, p := range parts {
+		if p == "" {
+			return nil, invalid(input, "empty prerelease identifier")
+		}
+		if !isIdentChars(p) {
+			return nil, invalid(input, fmt.Sprintf("invalid character in prerelease identifier %q", p))
+		}
+		if mode == Strict && len(p) > 1 && p[0] == '0' && isDigits(p) {
+			return nil, invalid(input, fmt.Sprintf("leading zero in prerelease identifier %q", p))
+		}
+		ids = append(ids, Identifier(p))
+	}
+	return ids, nil
+}
+
+func parseBuild(input, s string) ([]string, error) {
+	parts := strings.Split(s, ".")
+	for _, p := range parts {
+		if p == "" {
+			return nil, invalid(input, "empty build metadata identifier")
+		}
+		if !isIdentChars(p) {
+			return nil, invalid(input, fmt.Sprintf("invalid character in build metadata %q", p))
+		}
+	}
+	return parts, nil
+}
+
+func invalid(input, reason string) error {
+	return &ParseError{Input: input, Reason: reason}
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdentChars reports whether s only contains [0-9A-Za-z-].
+func isIdentChars(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
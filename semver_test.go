@@ -0,0 +1,167 @@
+package version
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse_Valid(t *testing.T) {
+	tests := []struct {
+		input string
+		major int
+		minor int
+		patch int
+		pre   []Identifier
+		build []string
+	}{
+		{"0.0.0", 0, 0, 0, nil, nil},
+		{"1.2.3", 1, 2, 3, nil, nil},
+		{"v1.2.3", 1, 2, 3, nil, nil},
+		{"1.0.0-alpha", 1, 0, 0, []Identifier{"alpha"}, nil},
+		{"1.0.0-alpha.1", 1, 0, 0, []Identifier{"alpha", "1"}, nil},
+		{"1.0.0-0.3.7", 1, 0, 0, []Identifier{"0", "3", "7"}, nil},
+		{"1.0.0-x.7.z.92", 1, 0, 0, []Identifier{"x", "7", "z", "92"}, nil},
+		{"1.0.0-x-y-z.--", 1, 0, 0, []Identifier{"x-y-z", "--"}, nil},
+		{"1.0.0+20130313144700", 1, 0, 0, nil, []string{"20130313144700"}},
+		{"1.0.0-beta+exp.sha.5114f85", 1, 0, 0, []Identifier{"beta"}, []string{"exp", "sha", "5114f85"}},
+		{"1.0.0+21AF26D3----117B344092BD", 1, 0, 0, nil, []string{"21AF26D3----117B344092BD"}},
+		{"1.0.0+001", 1, 0, 0, nil, []string{"001"}},
+		{"v0.0.0-20240101120000-abcdef123456", 0, 0, 0, []Identifier{"20240101120000-abcdef123456"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if v.Raw != tt.input {
+				t.Errorf("Raw = %q, want %q", v.Raw, tt.input)
+			}
+			if v.Major != tt.major || v.Minor != tt.minor || v.Patch != tt.patch {
+				t.Errorf("version = %d.%d.%d, want %d.%d.%d", v.Major, v.Minor, v.Patch, tt.major, tt.minor, tt.patch)
+			}
+			if !reflect.DeepEqual(v.Prerelease, tt.pre) {
+				t.Errorf("Prerelease = %v, want %v", v.Prerelease, tt.pre)
+			}
+			if !reflect.DeepEqual(v.Build, tt.build) {
+				t.Errorf("Build = %v, want %v", v.Build, tt.build)
+			}
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"v",
+		"1",
+		"1.2",
+		"1.2.3.4",
+		"01.2.3",
+		"1.02.3",
+		"1.2.03",
+		"1.2.3-",
+		"1.2.3-01",
+		"1.2.3-alpha..1",
+		"1.2.3-alpha_1",
+		"1.2.3+",
+		"1.2.3+meta..x",
+		"1.2.3+meta$",
+		"a.b.c",
+		"1.2.-3",
+		" 1.2.3",
+		"vv1.2.3",
+		"99999999999999999999.0.0",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			v, err := Parse(input)
+			if err == nil {
+				t.Fatalf("Parse(%q) = %v, want error", input, v)
+			}
+			if !errors.Is(err, ErrInvalidVersion) {
+				t.Errorf("errors.Is(err, ErrInvalidVersion) = false for %v", err)
+			}
+			var perr *ParseError
+			if !errors.As(err, &perr) || perr.Input != input {
+				t.Errorf("error should be *ParseError with Input %q, got %#v", input, err)
+			}
+			if !reflect.DeepEqual(v, Version{}) {
+				t.Errorf("Parse(%q) returned non-zero version %+v on error", input, v)
+			}
+		})
+	}
+}
+
+func TestParseWithMode_Lenient(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"1", "1.0.0"},
+		{"1.2", "1.2.0"},
+		{"v1.2", "1.2.0"},
+		{" 1.2.3 ", "1.2.3"},
+		{"01.02.03", "1.2.3"},
+		{"1.2-rc.01", "1.2.0-rc.01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := ParseWithMode(tt.input, Lenient)
+			if err != nil {
+				t.Fatalf("ParseWithMode(%q, Lenient) error = %v", tt.input, err)
+			}
+			if got := v.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseWithMode("1.2.3.4", Lenient); err == nil {
+		t.Error("Lenient mode should still reject four components")
+	}
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	for _, s := range []string{"1.2.3", "1.0.0-rc.1", "1.0.0-beta+exp.sha.5114f85", "10.20.30+build"} {
+		v, err := Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", s, err)
+		}
+		if v.String() != s {
+			t.Errorf("Parse(%q).String() = %q", s, v.String())
+		}
+	}
+}
+
+func TestMustParse_Panics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParse should panic on invalid input")
+		}
+	}()
+	MustParse("not-a-version")
+}
+
+func TestIdentifier_IsNumeric(t *testing.T) {
+	tests := []struct {
+		id   Identifier
+		want bool
+	}{
+		{"1", true},
+		{"0", true},
+		{"123", true},
+		{"rc", false},
+		{"1a", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := tt.id.IsNumeric(); got != tt.want {
+			t.Errorf("Identifier(%q).IsNumeric() = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@
 //   - ldflags: Build-time injection via -X flags (loaded automatically in init)
 //   - debug.ReadBuildInfo: Module version and VCS info from go install builds
 //   - Version file: Call LoadFromFile() to load from a .version file
+//   - Environment: Call LoadFromEnv() to read VERSION, GIT_COMMIT, ... variables
 //   - Git: Call LoadFromGit() to detect from git repository
 //
 // # Build with ldflags
@@ -15,8 +16,31 @@
 //
 // # Load from file or git
 //
-//	version.LoadFromFile(".version")  // load from specific file
-//	version.LoadFromGit()             // detect from git repo
+//	version.LoadFromFile(".version")         // load from specific file
+//	version.LoadFromFS(embedded, ".version") // load from a //go:embed file
+//	version.LoadFromGit()                    // detect from git repo
+//
+// # Custom sources
+//
+// Every source is a Provider. Resolve merges providers in order; by default
+// the first non-empty value of a field wins, SetPrecedence changes that per
+// field:
+//
+//	version.SetPrecedence(version.FieldVersion, version.LastWins)
+//	err := version.Resolve(
+//	    version.FileProvider("/etc/myapp/.version"),
+//	    vaultProvider, // any type with Load(ctx) (version.Partial, error)
+//	)
+//
+// # Multiple components
+//
+// Libraries that report their own version separately from the host binary
+// can create an independent Info:
+//
+//	var pluginVersion = version.New(
+//	    version.WithAppInfo("myplugin", "My plugin"),
+//	    version.WithVersion("1.4.0"),
+//	)
 //
 // # Basic usage
 //
@@ -29,12 +53,10 @@
 package version
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"runtime/debug"
-	"strconv"
+	"io/fs"
+	"sort"
 	"strings"
 	"time"
 )
@@ -47,6 +69,8 @@ type AppInfo struct {
 	Description string
 	// changelog for this build
 	Changelog string
+	// parsed changelog, populated by SetChangelog and SetChangelogFromFile
+	Changes Changelog
 }
 
 // GitInfo git details for the this build od the app
@@ -57,19 +81,26 @@ type GitInfo struct {
 	Branch string
 	// git repo
 	Repo string
+	// whether tracked files had uncommitted changes
+	Dirty bool
+	// version in git describe format, e.g. v1.2.3-4-gabc1234; zero unless
+	// the version was set to such a string
+	Describe Describe
 }
 
 // BuildInfo build timestamp and git information for the repo
 type BuildInfo struct {
 	Timestamp time.Time
 	Git       GitInfo
+	// custom labels such as a CI pipeline ID or release channel
+	Extra map[string]string
 }
 
 // Version application version details
 type Version struct {
-	// single string message
+	// version string exactly as provided
 	Raw string
-	// version prefix
+	// Deprecated: Prefix holds the prerelease as a single string, use Prerelease.
 	Prefix string
 	// major version part
 	Major int
@@ -77,13 +108,13 @@ type Version struct {
 	Minor int
 	// path version path
 	Patch int
+	// prerelease identifiers, e.g. [rc 1] for 1.2.3-rc.1
+	Prerelease []Identifier
+	// build metadata identifiers, e.g. [build 42] for 1.2.3+build.42
+	Build []string
 }
 
 var (
-	build   = BuildInfo{}
-	version = Version{}
-	app     = AppInfo{}
-
 	// BuildTimestamp ...
 	BuildTimestamp = ""
 	// GitCommit ...
@@ -94,52 +125,44 @@ var (
 	GitRepo = ""
 	// VersionInfo ...
 	VersionInfo = ""
+	// GitDirty is "true" if the working tree had uncommitted changes, "false"
+	// if it was clean
+	GitDirty = ""
+	// ExtraInfo holds URL-encoded extra labels, e.g. pipeline=1234&channel=beta
+	ExtraInfo = ""
 )
 
 func init() {
-	// Load from ldflags if provided at build time
-	SetBuildInfo(BuildTimestamp)
-	SetGitInfo(GitCommit, GitBranch, GitRepo)
-	SetVersion(VersionInfo)
-
-	// Fallback: use module version and VCS info from go install builds
-	loadFromBuildInfo()
+	// Load from ldflags if provided at build time, falling back to the
+	// module version and VCS info from go install builds
+	_ = std.Resolve(LdflagsProvider(), BuildInfoProvider())
 }
 
-// loadFromBuildInfo extracts version and VCS metadata from runtime/debug.ReadBuildInfo.
-// This provides correct version info for binaries built with go install.
-func loadFromBuildInfo() {
-	info, ok := debug.ReadBuildInfo()
-	if !ok {
-		return
-	}
-
-	// Use module version if ldflags didn't set one
-	if version.Raw == "" && info.Main.Version != "" && info.Main.Version != "(devel)" {
-		SetVersion(info.Main.Version)
+// String returns the canonical SemVer form, e.g. 1.2.3-rc.1+build.42
+func (ver Version) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d.%d.%d", ver.Major, ver.Minor, ver.Patch)
+	if pre := ver.PrereleaseString(); pre != "" {
+		sb.WriteString("-")
+		sb.WriteString(pre)
 	}
-
-	// Extract VCS settings (available in Go 1.18+)
-	var revision, vcsTime string
-	for _, s := range info.Settings {
-		switch s.Key {
-		case "vcs.revision":
-			revision = s.Value
-		case "vcs.time":
-			vcsTime = s.Value
-		}
+	if len(ver.Build) > 0 {
+		sb.WriteString("+")
+		sb.WriteString(strings.Join(ver.Build, "."))
 	}
+	return sb.String()
+}
 
-	if build.Git.Commit == "" && revision != "" {
-		build.Git.Commit = revision
+// PrereleaseString returns the prerelease identifiers joined with dots.
+func (ver Version) PrereleaseString() string {
+	if len(ver.Prerelease) == 0 {
+		return ver.Prefix
 	}
-	if build.Timestamp.IsZero() && vcsTime != "" {
-		SetBuildInfo(vcsTime)
+	ids := make([]string, len(ver.Prerelease))
+	for i, id := range ver.Prerelease {
+		ids[i] = string(id)
 	}
-}
-
-func (ver Version) String() string {
-	return fmt.Sprintf("%s %d.%d.%d", ver.Prefix, ver.Major, ver.Minor, ver.Patch)
+	return strings.Join(ids, ".")
 }
 
 func (app AppInfo) String() string {
@@ -147,210 +170,182 @@ func (app AppInfo) String() string {
 }
 
 func (build BuildInfo) String() string {
-	return fmt.Sprintf("Timestamp : %v, Git: %v", build.Timestamp, build.Git)
+	s := fmt.Sprintf("Timestamp : %v, Git: %v", build.Timestamp, build.Git)
+	if len(build.Extra) == 0 {
+		return s
+	}
+	labels := make([]string, 0, len(build.Extra))
+	for _, k := range sortedKeys(build.Extra) {
+		labels = append(labels, k+"="+build.Extra[k])
+	}
+	return s + ", Extra: " + strings.Join(labels, " ")
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func (git GitInfo) String() string {
-	return fmt.Sprintf("Repo: %s, Branch: %s, Commit: %s", git.Repo, git.Branch, git.Commit)
+	s := fmt.Sprintf("Repo: %s, Branch: %s, Commit: %s", git.Repo, git.Branch, git.Commit)
+	if git.Dirty {
+		s += " (dirty)"
+	}
+	return s
 }
 
 // SetAppInfo ...
 func SetAppInfo(name, description string) {
-	if app.Name == "" {
-		app.Name = name
-		app.Description = description
-	}
+	std.SetAppInfo(name, description)
 }
 
 // SetGitInfo set git details for app
 func SetGitInfo(commit, branch, repo string) {
-	if build.Git.Commit == "" {
-		build.Git.Branch = branch
-		build.Git.Commit = commit
-		build.Git.Repo = repo
-	}
-}
-
-// supportedFormats lists time formats that SetBuildInfo will try when parsing timestamps.
-var supportedFormats = []string{
-	time.UnixDate,
-	time.RFC3339,
-	time.RFC1123,
-	time.RFC1123Z,
-	time.RFC822,
-	time.RFC850,
-	"2006-01-02T15:04:05",
-	"2006-01-02 15:04:05",
-	"2006-01-02",
+	std.SetGitInfo(commit, branch, repo)
 }
 
 // SetBuildInfo set build info
 func SetBuildInfo(timestamp string) {
-	if build.Timestamp.IsZero() {
-		for _, format := range supportedFormats {
-			if t, err := time.Parse(format, timestamp); err == nil {
-				build.Timestamp = t
-				return
-			}
-		}
-	}
+	std.SetBuildInfo(timestamp)
 }
 
-// SetChangelog set application changelog
-func SetChangelog(changelog string) {
-	if app.Changelog == "" {
-		app.Changelog = changelog
-	}
+// SetExtra sets an extra label if it is not already set.
+func SetExtra(key, value string) {
+	std.SetExtra(key, value)
 }
 
-// SetChangelogFromFile read changelog from a file
-func SetChangelogFromFile(path string) error {
-	if app.Changelog == "" {
-		b, err := os.ReadFile(path) // #nosec G304 -- reading user-specified version file
-		if err != nil {
-			return err
-		}
-		app.Changelog = string(b)
-	}
-	return nil
+// SetChangelog set application changelog. The text is parsed as Keep a
+// Changelog Markdown into App().Changes; a parse error is returned but the
+// raw text is kept.
+func SetChangelog(changelog string) error {
+	return std.SetChangelog(changelog)
 }
 
-// SetVersion ...
-func SetVersion(ver string) {
-	version.Raw = ver
-
-	// Strip 'v' prefix if present for parsing
-	versionStr := strings.TrimPrefix(ver, "v")
-
-	// Split into base version and suffix (if any)
-	// Handle formats like: 1.2.3, 1.2.3-dev, 1.2.3-dev.100
-	parts := strings.SplitN(versionStr, "-", 2)
-	baseVersion := parts[0]
-	suffix := ""
-	if len(parts) > 1 {
-		suffix = parts[1]
-	}
+// SetChangelogFromFile read changelog from a file, see SetChangelog
+func SetChangelogFromFile(path string) error {
+	return std.SetChangelogFromFile(path)
+}
 
-	// Parse the base version (X.Y.Z)
-	verparts := strings.Split(baseVersion, ".")
-	if len(verparts) >= 3 {
-		version.Major, _ = strconv.Atoi(verparts[0])
-		version.Minor, _ = strconv.Atoi(verparts[1])
-		version.Patch, _ = strconv.Atoi(verparts[2])
-		version.Prefix = suffix
-	}
+// SetVersion parses ver as a semantic version (see Parse) and sets it as the
+// application version. Raw is always updated, even when ver is invalid; in that
+// case the numeric fields are left zero and the parse error is returned.
+func SetVersion(ver string) error {
+	return std.SetVersion(ver)
 }
 
 // Get ...
 func Get() Version {
-	return version
+	return std.Get()
 }
 
 // Build ...
 func Build() BuildInfo {
-	return build
+	return std.Build()
 }
 
 // Git ...
 func Git() GitInfo {
-	return build.Git
+	return std.Git()
 }
 
 // App ...
 func App() AppInfo {
-	return app
+	return std.App()
+}
+
+// Snapshot returns the application, version and build info of the default
+// Info, see Info.Snapshot.
+func Snapshot() Metadata {
+	return std.Snapshot()
+}
+
+// ChangesSince returns the changelog releases newer than v, e.g. to show
+// "what's new" since the version a user last ran.
+func ChangesSince(v Version) []Release {
+	return std.ChangesSince(v)
+}
+
+// Provenance returns the source of every field that has a value, e.g. to
+// tell whether Git().Commit came from -X GitCommit or from vcs.revision.
+func Provenance() Sources {
+	return std.Provenance()
 }
 
 // Print ...
 func Print() {
-	fmt.Println("Running:", App())
-	fmt.Println("Version:", Get())
-	fmt.Println("Build:", Build())
+	std.Print()
+}
+
+// PrintVerbose prints the same as Print followed by the source of every field.
+func PrintVerbose() {
+	std.PrintVerbose()
 }
 
-// LoadFromFile loads version information from a key=value file.
-// Keys: VERSION, GIT_COMMIT, GIT_BRANCH, GIT_REPO, BUILD_TIMESTAMP
+// LoadFromFile loads version information from a version file, see
+// Info.LoadFromFile.
 func LoadFromFile(path string) error {
-	file, err := os.Open(path) // #nosec G304 -- reading user-specified version file
-	if err != nil {
-		return err
-	}
-	defer func() { _ = file.Close() }()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "VERSION":
-			if version.Raw == "" {
-				SetVersion(value)
-			}
-		case "GIT_COMMIT":
-			if build.Git.Commit == "" {
-				build.Git.Commit = value
-			}
-		case "GIT_BRANCH":
-			if build.Git.Branch == "" {
-				build.Git.Branch = value
-			}
-		case "GIT_REPO":
-			if build.Git.Repo == "" {
-				build.Git.Repo = value
-			}
-		case "BUILD_TIMESTAMP":
-			if build.Timestamp.IsZero() {
-				SetBuildInfo(value)
-			}
-		}
-	}
-	return scanner.Err()
+	return std.LoadFromFile(path)
 }
 
-// LoadFromGit reads version information directly from git commands.
+// LoadFromGit reads version information from the git repository containing
+// the working directory without the git binary, see Info.LoadFromGit.
 // This is useful during development with 'go run'.
 func LoadFromGit() error {
-	// Check if we're in a git repository
-	if err := exec.Command("git", "rev-parse", "--git-dir").Run(); err != nil {
-		return err
-	}
+	return std.LoadFromGit()
+}
 
-	// Get commit hash
-	if build.Git.Commit == "" {
-		if out, err := exec.Command("git", "rev-parse", "HEAD").Output(); err == nil {
-			build.Git.Commit = strings.TrimSpace(string(out))
-		}
-	}
+// LoadFromFS loads version information from the file name in fsys, e.g. an
+// embed.FS, see Info.LoadFromFS.
+func LoadFromFS(fsys fs.FS, name string) error {
+	return std.LoadFromFS(fsys, name)
+}
 
-	// Get branch name
-	if build.Git.Branch == "" {
-		if out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output(); err == nil {
-			build.Git.Branch = strings.TrimSpace(string(out))
-		}
-	}
+// LoadFromBytes loads version information from version file content.
+func LoadFromBytes(data []byte) error {
+	return std.LoadFromBytes(data)
+}
 
-	// Get remote URL
-	if build.Git.Repo == "" {
-		if out, err := exec.Command("git", "remote", "get-url", "origin").Output(); err == nil {
-			build.Git.Repo = strings.TrimSpace(string(out))
-		}
-	}
+// LoadFromEnv loads version information from environment variables with the
+// given prefix and common CI variables, see EnvProvider.
+func LoadFromEnv(prefix string) error {
+	return std.LoadFromEnv(prefix)
+}
 
-	// Get version from git describe (tags)
-	if version.Raw == "" {
-		if out, err := exec.Command("git", "describe", "--tags", "--always").Output(); err == nil {
-			SetVersion(strings.TrimSpace(string(out)))
-		}
-	}
+// DefaultLocations returns the paths LoadFromDefaultLocations searches, see
+// Info.DefaultLocations.
+func DefaultLocations() []string {
+	return std.DefaultLocations()
+}
+
+// LoadFromDefaultLocations loads the first .version file found in
+// DefaultLocations and returns its path.
+func LoadFromDefaultLocations() (string, error) {
+	return std.LoadFromDefaultLocations()
+}
+
+// AutoLoadDefaultLocations calls LoadFromDefaultLocations as soon as the
+// application name is set, see Info.AutoLoadDefaultLocations.
+func AutoLoadDefaultLocations() {
+	std.AutoLoadDefaultLocations()
+}
+
+// Resolve loads the providers in order and merges them into the default
+// Info, see Info.Resolve.
+func Resolve(providers ...Provider) error {
+	return std.Resolve(providers...)
+}
+
+// ResolveContext is like Resolve but passes ctx to the providers.
+func ResolveContext(ctx context.Context, providers ...Provider) error {
+	return std.ResolveContext(ctx, providers...)
+}
 
-	return nil
+// SetPrecedence sets how Resolve merges a field of the default Info.
+func SetPrecedence(f Field, p Precedence) {
+	std.SetPrecedence(f, p)
 }
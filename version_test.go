@@ -2,6 +2,7 @@ package version
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,11 +12,9 @@ import (
 	"time"
 )
 
-// resetState clears all package-level globals so tests don't interfere with each other.
+// resetState replaces the default instance so tests don't interfere with each other.
 func resetState() {
-	version = Version{}
-	build = BuildInfo{}
-	app = AppInfo{}
+	std = New()
 }
 
 // --- SetVersion tests ---
@@ -117,15 +116,34 @@ func TestSetVersion_TooFewParts(t *testing.T) {
 
 func TestSetVersion_NonNumeric(t *testing.T) {
 	resetState()
-	SetVersion("abc.def.ghi")
+	if err := SetVersion("abc.def.ghi"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("SetVersion() error = %v, want ErrInvalidVersion", err)
+	}
 
 	v := Get()
-	// strconv.Atoi returns 0 on failure
+	if v.Raw != "abc.def.ghi" {
+		t.Errorf("Raw = %q, want %q (Raw is kept on error)", v.Raw, "abc.def.ghi")
+	}
 	if v.Major != 0 || v.Minor != 0 || v.Patch != 0 {
 		t.Errorf("version = %d.%d.%d, want 0.0.0 for non-numeric", v.Major, v.Minor, v.Patch)
 	}
 }
 
+func TestSetVersion_BuildMetadata(t *testing.T) {
+	resetState()
+	if err := SetVersion("1.2.3+meta"); err != nil {
+		t.Fatalf("SetVersion() error = %v", err)
+	}
+
+	v := Get()
+	if v.Patch != 3 {
+		t.Errorf("Patch = %d, want 3", v.Patch)
+	}
+	if len(v.Build) != 1 || v.Build[0] != "meta" {
+		t.Errorf("Build = %v, want [meta]", v.Build)
+	}
+}
+
 func TestSetVersion_Overwrites(t *testing.T) {
 	resetState()
 	SetVersion("1.0.0")
@@ -344,17 +362,22 @@ func TestVersionString(t *testing.T) {
 		{
 			name: "basic version",
 			ver:  Version{Major: 1, Minor: 2, Patch: 3},
-			want: " 1.2.3",
+			want: "1.2.3",
 		},
 		{
 			name: "with suffix",
 			ver:  Version{Major: 1, Minor: 0, Patch: 0, Prefix: "beta"},
-			want: "beta 1.0.0",
+			want: "1.0.0-beta",
+		},
+		{
+			name: "prerelease and build",
+			ver:  Version{Major: 1, Minor: 2, Patch: 3, Prerelease: []Identifier{"rc", "1"}, Build: []string{"build", "42"}},
+			want: "1.2.3-rc.1+build.42",
 		},
 		{
 			name: "zero version",
 			ver:  Version{},
-			want: " 0.0.0",
+			want: "0.0.0",
 		},
 	}
 
@@ -406,6 +429,13 @@ func TestGitInfoString(t *testing.T) {
 	if !strings.Contains(got, "github.com/user/repo") {
 		t.Errorf("String() = %q, should contain repo", got)
 	}
+	if strings.Contains(got, "dirty") {
+		t.Errorf("String() = %q, clean tree should not be marked dirty", got)
+	}
+	g.Dirty = true
+	if got := g.String(); !strings.Contains(got, "(dirty)") {
+		t.Errorf("String() = %q, should be marked dirty", got)
+	}
 }
 
 // --- Print test ---
@@ -565,7 +595,7 @@ func TestLoadFromFile_EmptyFile(t *testing.T) {
 	}
 }
 
-func TestLoadFromFile_IgnoresUnknownKeys(t *testing.T) {
+func TestLoadFromFile_UnknownKeysAreExtra(t *testing.T) {
 	resetState()
 
 	dir := t.TempDir()
@@ -584,6 +614,9 @@ ANOTHER=thing
 	if v.Raw != "1.0.0" {
 		t.Errorf("Raw = %q, want %q", v.Raw, "1.0.0")
 	}
+	if extra := Build().Extra; extra["UNKNOWN_KEY"] != "some_value" || extra["ANOTHER"] != "thing" {
+		t.Errorf("Extra = %v, want unknown keys as labels", extra)
+	}
 }
 
 func TestLoadFromFile_IgnoresLinesWithoutEquals(t *testing.T) {
@@ -743,7 +776,7 @@ func TestLoadFromBuildInfo_DoesNotOverwriteLdflags(t *testing.T) {
 	SetBuildInfo("Mon Jan  2 15:04:05 UTC 2006")
 
 	// loadFromBuildInfo should not overwrite any of these
-	loadFromBuildInfo()
+	std.loadFromBuildInfo()
 
 	v := Get()
 	if v.Raw != "1.0.0" {
@@ -760,7 +793,7 @@ func TestLoadFromBuildInfo_FillsEmptyState(t *testing.T) {
 	resetState()
 
 	// With empty state, loadFromBuildInfo should populate from debug.ReadBuildInfo
-	loadFromBuildInfo()
+	std.loadFromBuildInfo()
 
 	// We're running under 'go test', so ReadBuildInfo returns "(devel)" for
 	// Main.Version, which should be skipped. But VCS info should be present
@@ -791,7 +824,9 @@ func TestSetVersion_Table(t *testing.T) {
 		{"empty", "", "", 0, 0, 0, ""},
 		{"one part", "1", "1", 0, 0, 0, ""},
 		{"two parts", "1.2", "1.2", 0, 0, 0, ""},
-		{"four parts", "1.2.3.4", "1.2.3.4", 1, 2, 3, ""},
+		{"four parts", "1.2.3.4", "1.2.3.4", 0, 0, 0, ""},
+		{"build metadata", "1.2.3+meta", "1.2.3+meta", 1, 2, 3, ""},
+		{"prerelease and build", "v1.2.3-rc.1+build.7", "v1.2.3-rc.1+build.7", 1, 2, 3, "rc.1"},
 		{"just v", "v", "v", 0, 0, 0, ""},
 		{"v only prefix", "v1", "v1", 0, 0, 0, ""},
 	}
@@ -897,3 +932,10 @@ BUILD_TIMESTAMP=%s
 		t.Error("Timestamp should not be zero")
 	}
 }
+
+func TestBuildInfoString_Extra(t *testing.T) {
+	b := BuildInfo{Extra: map[string]string{"z": "1", "a": "2"}}
+	if s := b.String(); !strings.HasSuffix(s, ", Extra: a=2 z=1") {
+		t.Errorf("String() = %q", s)
+	}
+}